@@ -0,0 +1,163 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AccessLogFormat specifies the layout of the lines written by an AccessLog.
+type AccessLogFormat int
+
+const (
+	CommonLogFormat   AccessLogFormat = iota // NCSA Common Log Format
+	CombinedLogFormat                        // NCSA Combined Log Format, Common with Referer and User-Agent
+	JSONLogFormat                            // one JSON object per line, see AccessLogEntry
+)
+
+// ncsaTimeLayout is the layout of the time in the NCSA formats
+const ncsaTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogEntry holds the fields of one logged request. It is the object written for JSONLogFormat.
+type AccessLogEntry struct {
+	Time      time.Time `json:"time"`
+	Remote    string    `json:"remote"` // host of the client, without port
+	User      string    `json:"user,omitempty"`
+	Method    string    `json:"method"`
+	URI       string    `json:"uri"`
+	Proto     string    `json:"proto"`
+	Status    int       `json:"status"`
+	Bytes     int       `json:"bytes"`
+	Duration  float64   `json:"duration"`            // seconds
+	Route     string    `json:"route,omitempty"`     // Path template of the Route
+	Operation string    `json:"operation,omitempty"` // Operation of the Route
+	Referer   string    `json:"referer,omitempty"`
+	UserAgent string    `json:"userAgent,omitempty"`
+}
+
+// AccessLog is used to create a FilterFunction that writes one line for each request to an io.Writer.
+// Install its Filter for a Container (to include requests that do not match a Route) or a WebService.
+//
+//	accessLog := restful.NewAccessLog(os.Stdout, restful.CombinedLogFormat)
+//	restful.Filter(accessLog.Filter)
+type AccessLog struct {
+	Format     AccessLogFormat
+	SampleRate float64 // fraction (0..1] of requests to log ; zero means all requests are logged
+	writer     io.Writer
+	protection sync.Mutex // serializes writes and the use of random
+	random     *rand.Rand
+}
+
+// NewAccessLog returns an AccessLog that writes lines in the format to the writer.
+func NewAccessLog(writer io.Writer, format AccessLogFormat) *AccessLog {
+	return &AccessLog{
+		Format: format,
+		writer: writer,
+		random: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Filter is a FilterFunction that logs the request after processing the remaining chain.
+// The status and number of bytes are taken from Response.StatusCode and Response.ContentLength.
+func (a *AccessLog) Filter(req *Request, resp *Response, chain *FilterChain) {
+	start := time.Now()
+	chain.ProcessFilter(req, resp)
+	if !a.sampled() {
+		return
+	}
+	entry := AccessLogEntry{
+		Time:      start,
+		Remote:    remoteHost(req.Request.RemoteAddr),
+		User:      requestUser(req),
+		Method:    req.Request.Method,
+		URI:       req.Request.URL.RequestURI(),
+		Proto:     req.Request.Proto,
+		Status:    resp.StatusCode(),
+		Bytes:     resp.ContentLength(),
+		Duration:  time.Since(start).Seconds(),
+		Referer:   req.Request.Referer(),
+		UserAgent: req.Request.UserAgent()}
+	if req.selectedRoute != nil {
+		entry.Route, entry.Operation = req.selectedRoute.Path, req.selectedRoute.Operation
+	}
+	a.write(entry)
+}
+
+// sampled returns whether the current request must be logged.
+func (a *AccessLog) sampled() bool {
+	if a.SampleRate <= 0 || a.SampleRate >= 1 {
+		return true
+	}
+	a.protection.Lock()
+	defer a.protection.Unlock()
+	return a.random.Float64() < a.SampleRate
+}
+
+// write formats the entry and writes it as a single line.
+func (a *AccessLog) write(entry AccessLogEntry) {
+	var line bytes.Buffer
+	switch a.Format {
+	case JSONLogFormat:
+		json.NewEncoder(&line).Encode(entry) // adds the newline
+	default:
+		line.WriteString(orDash(entry.Remote))
+		line.WriteString(" - ")
+		line.WriteString(orDash(entry.User))
+		line.WriteString(" [")
+		line.WriteString(entry.Time.Format(ncsaTimeLayout))
+		line.WriteString("] \"")
+		line.WriteString(entry.Method + " " + entry.URI + " " + entry.Proto)
+		line.WriteString("\" ")
+		line.WriteString(strconv.Itoa(entry.Status))
+		line.WriteString(" ")
+		if entry.Bytes == 0 {
+			line.WriteString("-")
+		} else {
+			line.WriteString(strconv.Itoa(entry.Bytes))
+		}
+		if a.Format == CombinedLogFormat {
+			line.WriteString(" " + strconv.Quote(orDash(entry.Referer)) + " " + strconv.Quote(orDash(entry.UserAgent)))
+		}
+		line.WriteString("\n")
+	}
+	a.protection.Lock()
+	defer a.protection.Unlock()
+	a.writer.Write(line.Bytes())
+}
+
+// remoteHost returns the host part of a remote address ; IPv6 addresses are returned without brackets.
+func remoteHost(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr // no port
+	}
+	return host
+}
+
+// requestUser returns the user from the URL or from the Basic Authorization header, empty if absent.
+func requestUser(req *Request) string {
+	if req.Request.URL.User != nil {
+		if name := req.Request.URL.User.Username(); name != "" {
+			return name
+		}
+	}
+	if name, _, ok := req.Request.BasicAuth(); ok {
+		return name
+	}
+	return ""
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,79 @@
+package restful
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newLoggedContainer(accessLog *AccessLog) *Container {
+	container := NewContainer()
+	container.Filter(accessLog.Filter)
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{id}").Operation("findUser").To(func(req *Request, resp *Response) {
+		resp.Write([]byte("ernest"))
+	}))
+	container.Add(ws)
+	return container
+}
+
+func dispatchLogged(container *Container, remoteAddr string) {
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/users/42?x=1", nil)
+	httpRequest.RemoteAddr = remoteAddr
+	httpRequest.Header.Set("Referer", "http://there.com")
+	httpRequest.Header.Set("User-Agent", "test/1.0")
+	httpRequest.SetBasicAuth("admin", "secret")
+	container.dispatch(httptest.NewRecorder(), httpRequest)
+}
+
+// go test -v -test.run TestAccessLog_Common ...restful
+func TestAccessLog_Common(t *testing.T) {
+	var buffer bytes.Buffer
+	dispatchLogged(newLoggedContainer(NewAccessLog(&buffer, CommonLogFormat)), "[2001:db8::1]:51234")
+	line := buffer.String()
+	if !strings.HasPrefix(line, "2001:db8::1 - admin [") || !strings.HasSuffix(line, `] "GET /users/42?x=1 HTTP/1.1" 200 6`+"\n") {
+		t.Errorf("unexpected line:%q", line)
+	}
+}
+
+// go test -v -test.run TestAccessLog_Combined ...restful
+func TestAccessLog_Combined(t *testing.T) {
+	var buffer bytes.Buffer
+	dispatchLogged(newLoggedContainer(NewAccessLog(&buffer, CombinedLogFormat)), "10.0.0.1:8080")
+	line := buffer.String()
+	if !strings.HasPrefix(line, "10.0.0.1 - admin [") || !strings.HasSuffix(line, `200 6 "http://there.com" "test/1.0"`+"\n") {
+		t.Errorf("unexpected line:%q", line)
+	}
+}
+
+// go test -v -test.run TestAccessLog_JSON ...restful
+func TestAccessLog_JSON(t *testing.T) {
+	var buffer bytes.Buffer
+	dispatchLogged(newLoggedContainer(NewAccessLog(&buffer, JSONLogFormat)), "10.0.0.1:8080")
+	entry := AccessLogEntry{}
+	if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Route != "/users/{id}" || entry.Operation != "findUser" || entry.Status != 200 || entry.Bytes != 6 || entry.Remote != "10.0.0.1" {
+		t.Errorf("unexpected entry:%#v", entry)
+	}
+	if strings.Count(buffer.String(), "\n") != 1 {
+		t.Errorf("expected a single line:%q", buffer.String())
+	}
+}
+
+func TestAccessLog_Sampling(t *testing.T) {
+	var buffer bytes.Buffer
+	accessLog := NewAccessLog(&buffer, CommonLogFormat)
+	accessLog.SampleRate = 0.000001
+	container := newLoggedContainer(accessLog)
+	for i := 0; i < 10; i++ {
+		dispatchLogged(container, "10.0.0.1:8080")
+	}
+	if buffer.Len() > 0 {
+		t.Errorf("expected no lines:%s", buffer.String())
+	}
+}
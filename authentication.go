@@ -0,0 +1,214 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// PrincipalAttribute is the name of the Request attribute that holds the principal resolved by the Authentication filter.
+const PrincipalAttribute = "restful.principal"
+
+// ErrNoCredentials is returned by an Authenticator if the request has no credentials for its scheme.
+var ErrNoCredentials = errors.New("[restful] no credentials")
+
+// Authenticator resolves the principal (e.g. a user) of a request using one authentication scheme.
+type Authenticator interface {
+	// SchemeName identifies the scheme in the Security declarations of Routes and WebServices
+	// and in the API documentation.
+	SchemeName() string
+	// Authenticate returns the principal of the request. It returns ErrNoCredentials if the request
+	// has no credentials for this scheme or another error if the credentials are invalid.
+	Authenticate(req *Request) (interface{}, error)
+	// Challenge returns the value of the WWW-Authenticate header of a 401 response ; empty if none.
+	Challenge() string
+}
+
+// PasswordFunction validates a username and password and returns the principal, or an error if they are invalid.
+type PasswordFunction func(username, password string) (interface{}, error)
+
+// TokenFunction validates a token (or key) and returns the principal, or an error if it is invalid.
+type TokenFunction func(token string) (interface{}, error)
+
+// Authentication is used to create a FilterFunction that authenticates requests using Authenticators.
+// The Authenticators that apply to a Route are those named by its Security declaration (see RouteBuilder.Security
+// and WebService.Security) ; if a Route has none then all Authenticators apply. They are tried in the order given
+// and the first principal found is stored in the Request attribute PrincipalAttribute (see Request.Principal).
+// If no principal is found then a 401 ServiceError is written with a WWW-Authenticate header for each scheme.
+//
+//	auth := restful.NewAuthentication(
+//		restful.NewBasicAuthenticator("Orders", checkPassword),
+//		restful.NewAPIKeyAuthenticator("X-API-Key", restful.HEADER_PARAMETER, checkKey))
+//	ws.Filter(auth.Filter)
+//	ws.Route(ws.GET("/orders").Security("basic").To(listOrders))
+type Authentication struct {
+	authenticators []Authenticator
+}
+
+// NewAuthentication returns an Authentication that uses the authenticators in the given order.
+func NewAuthentication(authenticators ...Authenticator) *Authentication {
+	return &Authentication{authenticators: authenticators}
+}
+
+// Authenticators returns all Authenticators, e.g. to document them.
+func (a *Authentication) Authenticators() []Authenticator {
+	return a.authenticators
+}
+
+// Filter is a FilterFunction that resolves the principal of the request or responds with 401.
+// Requests for which no Route was selected are passed on unchanged.
+func (a *Authentication) Filter(req *Request, resp *Response, chain *FilterChain) {
+	if req.selectedRoute == nil {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	applicable := a.authenticatorsFor(req.selectedRoute)
+	for _, each := range applicable {
+		principal, err := each.Authenticate(req)
+		if err == ErrNoCredentials {
+			continue
+		}
+		if err != nil {
+			break
+		}
+		req.SetAttribute(PrincipalAttribute, principal)
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	for _, each := range applicable {
+		if challenge := each.Challenge(); challenge != "" {
+			resp.Header().Add("WWW-Authenticate", challenge)
+		}
+	}
+	resp.writeServiceError(NewError(http.StatusUnauthorized, "401: Unauthorized"))
+}
+
+// authenticatorsFor returns the Authenticators named by the Security declaration of the Route, or all if it has none.
+func (a *Authentication) authenticatorsFor(route *Route) []Authenticator {
+	if len(route.Security) == 0 {
+		return a.authenticators
+	}
+	selected := []Authenticator{}
+	for _, each := range a.authenticators {
+		for _, name := range route.Security {
+			if each.SchemeName() == name {
+				selected = append(selected, each)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// BasicAuthenticator is an Authenticator for the HTTP Basic scheme (RFC 7617).
+type BasicAuthenticator struct {
+	Name     string // scheme name, default is basic
+	Realm    string
+	Password PasswordFunction
+}
+
+// NewBasicAuthenticator returns a BasicAuthenticator named basic.
+func NewBasicAuthenticator(realm string, password PasswordFunction) *BasicAuthenticator {
+	return &BasicAuthenticator{Name: "basic", Realm: realm, Password: password}
+}
+
+// SchemeName is part of Authenticator
+func (b *BasicAuthenticator) SchemeName() string {
+	return b.Name
+}
+
+// Authenticate is part of Authenticator
+func (b *BasicAuthenticator) Authenticate(req *Request) (interface{}, error) {
+	username, password, ok := req.Request.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	return b.Password(username, password)
+}
+
+// Challenge is part of Authenticator
+func (b *BasicAuthenticator) Challenge() string {
+	return `Basic realm="` + b.Realm + `"`
+}
+
+// BearerAuthenticator is an Authenticator for the Bearer scheme (RFC 6750) ; the token is taken from the Authorization header.
+type BearerAuthenticator struct {
+	Name  string // scheme name, default is bearer
+	Realm string
+	Token TokenFunction
+}
+
+// NewBearerAuthenticator returns a BearerAuthenticator named bearer.
+func NewBearerAuthenticator(realm string, token TokenFunction) *BearerAuthenticator {
+	return &BearerAuthenticator{Name: "bearer", Realm: realm, Token: token}
+}
+
+// SchemeName is part of Authenticator
+func (b *BearerAuthenticator) SchemeName() string {
+	return b.Name
+}
+
+// Authenticate is part of Authenticator
+func (b *BearerAuthenticator) Authenticate(req *Request) (interface{}, error) {
+	token, ok := bearerToken(req.Request)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	return b.Token(token)
+}
+
+// Challenge is part of Authenticator
+func (b *BearerAuthenticator) Challenge() string {
+	return `Bearer realm="` + b.Realm + `"`
+}
+
+// bearerToken returns the token of an Authorization header with the Bearer scheme (case-insensitive).
+func bearerToken(httpRequest *http.Request) (string, bool) {
+	const prefix = "bearer "
+	authorization := httpRequest.Header.Get("Authorization")
+	if len(authorization) <= len(prefix) || strings.ToLower(authorization[:len(prefix)]) != prefix {
+		return "", false
+	}
+	return strings.TrimSpace(authorization[len(prefix):]), true
+}
+
+// APIKeyAuthenticator is an Authenticator that takes a key from a header or query parameter.
+type APIKeyAuthenticator struct {
+	Name    string // scheme name, default is apiKey
+	KeyName string // name of the header or query parameter
+	In      int    // HEADER_PARAMETER or QUERY_PARAMETER
+	Key     TokenFunction
+}
+
+// NewAPIKeyAuthenticator returns an APIKeyAuthenticator named apiKey for a key passed in (HEADER_PARAMETER or QUERY_PARAMETER).
+func NewAPIKeyAuthenticator(keyName string, in int, key TokenFunction) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{Name: "apiKey", KeyName: keyName, In: in, Key: key}
+}
+
+// SchemeName is part of Authenticator
+func (k *APIKeyAuthenticator) SchemeName() string {
+	return k.Name
+}
+
+// Authenticate is part of Authenticator
+func (k *APIKeyAuthenticator) Authenticate(req *Request) (interface{}, error) {
+	var key string
+	if k.In == QUERY_PARAMETER {
+		key = req.Request.URL.Query().Get(k.KeyName)
+	} else {
+		key = req.Request.Header.Get(k.KeyName)
+	}
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	return k.Key(key)
+}
+
+// Challenge is part of Authenticator ; there is no standard challenge for API keys.
+func (k *APIKeyAuthenticator) Challenge() string {
+	return ""
+}
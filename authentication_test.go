@@ -0,0 +1,112 @@
+package restful
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func checkAdmin(username, password string) (interface{}, error) {
+	if username == "admin" && password == "secret" {
+		return username, nil
+	}
+	return nil, errors.New("invalid password")
+}
+
+func checkToken(token string) (interface{}, error) {
+	if token == "t0ken" {
+		return "token-user", nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+func newAuthenticationContainer() *Container {
+	auth := NewAuthentication(
+		NewBasicAuthenticator("Test", checkAdmin),
+		NewBearerAuthenticator("Test", checkToken),
+		NewAPIKeyAuthenticator("key", QUERY_PARAMETER, checkToken))
+	container := NewContainer()
+	ws := new(WebService).Path("/secret").Security("bearer", "apiKey")
+	ws.Filter(auth.Filter)
+	whoami := func(req *Request, resp *Response) {
+		resp.Write([]byte(req.Principal().(string)))
+	}
+	ws.Route(ws.GET("/basic").Security("basic").To(whoami))
+	ws.Route(ws.GET("/token").To(whoami))
+	container.Add(ws)
+	return container
+}
+
+// go test -v -test.run TestAuthenticationFilter ...restful
+func TestAuthenticationFilter(t *testing.T) {
+	container := newAuthenticationContainer()
+	for _, each := range []struct {
+		url, authorization string
+		status             int
+		body               string
+		challenges         []string
+	}{
+		{"/secret/basic", "Basic YWRtaW46c2VjcmV0", 200, "admin", nil},
+		{"/secret/basic", "Basic YWRtaW46YWRtaW4=", 401, "", []string{`Basic realm="Test"`}},
+		{"/secret/basic", "Bearer t0ken", 401, "", []string{`Basic realm="Test"`}},
+		{"/secret/token", "bearer t0ken", 200, "token-user", nil},
+		{"/secret/token?key=t0ken", "", 200, "token-user", nil},
+		{"/secret/token?key=wrong", "", 401, "", []string{`Bearer realm="Test"`}},
+		{"/secret/token", "Basic YWRtaW46c2VjcmV0", 401, "", []string{`Bearer realm="Test"`}},
+	} {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com"+each.url, nil)
+		if each.authorization != "" {
+			httpRequest.Header.Set("Authorization", each.authorization)
+		}
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		if httpWriter.Code != each.status {
+			t.Errorf("%s %s: expected %d got %d", each.url, each.authorization, each.status, httpWriter.Code)
+			continue
+		}
+		if each.status == 200 && httpWriter.Body.String() != each.body {
+			t.Errorf("%s: unexpected principal:%s", each.url, httpWriter.Body.String())
+		}
+		if got := httpWriter.Header()["Www-Authenticate"]; len(got) != len(each.challenges) || (len(got) > 0 && got[0] != each.challenges[0]) {
+			t.Errorf("%s: unexpected challenges:%v", each.url, got)
+		}
+	}
+}
+
+func TestAuthenticationFilterUsesServiceErrorHandler(t *testing.T) {
+	container := newAuthenticationContainer()
+	container.ServiceErrorHandler(WriteProblemDetails)
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/secret/token", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != 401 || httpWriter.Header().Get(HEADER_ContentType) != MIME_ProblemJSON {
+		t.Errorf("expected problem details, got:%d %v", httpWriter.Code, httpWriter.Header())
+	}
+	if got := httpWriter.Header().Get("WWW-Authenticate"); got != `Bearer realm="Test"` {
+		t.Errorf("unexpected challenge:%s", got)
+	}
+}
+
+func TestAuthenticationFilter_NoRoute(t *testing.T) {
+	container := newAuthenticationContainer()
+	container.Filter(NewAuthentication(NewBasicAuthenticator("Test", checkAdmin)).Filter)
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/missing", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != 404 {
+		t.Errorf("expected 404 got %d", httpWriter.Code)
+	}
+}
+
+func TestRouteSecurity(t *testing.T) {
+	ws := new(WebService).Path("/").Security("basic")
+	ws.Route(ws.GET("/a").To(dummy))
+	ws.Route(ws.GET("/b").Security("apiKey").To(dummy))
+	if got := ws.Routes()[0].Security; len(got) != 1 || got[0] != "basic" {
+		t.Errorf("expected WebService default, got %v", got)
+	}
+	if got := ws.Routes()[1].Security; len(got) != 1 || got[0] != "apiKey" {
+		t.Errorf("expected Route override, got %v", got)
+	}
+}
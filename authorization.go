@@ -0,0 +1,76 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import "net/http"
+
+// RoleHolder is implemented by principals that have roles.
+type RoleHolder interface {
+	HasRole(role string) bool
+}
+
+// ScopeHolder is implemented by principals that were granted (OAuth) scopes.
+type ScopeHolder interface {
+	HasScope(scope string) bool
+}
+
+// AuthorizationFilter is a FilterFunction that checks the principal of the request (see Request.Principal)
+// against the Roles and Scopes of the selected Route. The principal must have at least one of the Roles
+// (see RoleHolder) and all of the Scopes (see ScopeHolder). If the request has no principal then a 401 ServiceError
+// is written, otherwise a 403 ServiceError. Routes without Roles and Scopes are not checked.
+//
+// RouteBuilder.Build installs this filter as the last filter of a Route that declares Roles or Scopes,
+// such that it runs after the Authentication filter of the Container, WebService or Route.
+func AuthorizationFilter(req *Request, resp *Response, chain *FilterChain) {
+	route := req.selectedRoute
+	if route == nil || (len(route.Roles) == 0 && len(route.Scopes) == 0) {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	principal := req.Principal()
+	if principal == nil {
+		resp.writeServiceError(NewError(http.StatusUnauthorized, "401: Unauthorized"))
+		return
+	}
+	if !hasAnyRole(principal, route.Roles) || !hasAllScopes(principal, route.Scopes) {
+		resp.writeServiceError(NewError(http.StatusForbidden, "403: Forbidden"))
+		return
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// hasAnyRole returns whether the principal has one of the roles ; true if there are none.
+func hasAnyRole(principal interface{}, roles []string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	holder, ok := principal.(RoleHolder)
+	if !ok {
+		return false
+	}
+	for _, each := range roles {
+		if holder.HasRole(each) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAllScopes returns whether the principal has all of the scopes.
+func hasAllScopes(principal interface{}, scopes []string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	holder, ok := principal.(ScopeHolder)
+	if !ok {
+		return false
+	}
+	for _, each := range scopes {
+		if !holder.HasScope(each) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testPrincipal struct {
+	roles, scopes []string
+}
+
+func (p testPrincipal) HasRole(role string) bool {
+	return containsString(p.roles, role)
+}
+
+func (p testPrincipal) HasScope(scope string) bool {
+	return containsString(p.scopes, scope)
+}
+
+// go test -v -test.run TestAuthorizationFilter ...restful
+func TestAuthorizationFilter(t *testing.T) {
+	principals := map[string]interface{}{
+		"admin":  testPrincipal{roles: []string{"admin"}},
+		"reader": testPrincipal{roles: []string{"user"}, scopes: []string{"orders:read"}},
+		"writer": testPrincipal{roles: []string{"user"}, scopes: []string{"orders:read", "orders:write"}},
+		"plain":  "no roles or scopes"}
+	authenticate := func(req *Request, resp *Response, chain *FilterChain) {
+		if principal, ok := principals[req.HeaderParameter("X-User")]; ok {
+			req.SetAttribute(PrincipalAttribute, principal)
+		}
+		chain.ProcessFilter(req, resp)
+	}
+	container := NewContainer()
+	container.ServiceErrorHandler(WriteProblemDetails)
+	ws := new(WebService).Path("/orders").Roles("user", "admin")
+	ws.Filter(authenticate)
+	ws.Route(ws.GET("").To(dummy))
+	ws.Route(ws.PUT("").Scopes("orders:read", "orders:write").To(dummy))
+	ws.Route(ws.DELETE("").Roles("admin").To(dummy))
+	container.Add(ws)
+
+	for _, each := range []struct {
+		method, user string
+		status       int
+	}{
+		{"GET", "admin", 200},
+		{"GET", "reader", 200},
+		{"GET", "plain", 403},
+		{"GET", "", 401},
+		{"PUT", "writer", 200},
+		{"PUT", "reader", 403},
+		{"DELETE", "admin", 200},
+		{"DELETE", "writer", 403},
+	} {
+		httpRequest, _ := http.NewRequest(each.method, "http://here.com/orders", nil)
+		httpRequest.Header.Set("X-User", each.user)
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		if httpWriter.Code != each.status {
+			t.Errorf("%s as %q: expected %d got %d", each.method, each.user, each.status, httpWriter.Code)
+		}
+		if each.status != 200 && httpWriter.Header().Get(HEADER_ContentType) != MIME_ProblemJSON {
+			t.Errorf("%s as %q: expected problem details, got:%v", each.method, each.user, httpWriter.Header())
+		}
+	}
+}
+
+func TestRouteAuthorizationRequirements(t *testing.T) {
+	ws := new(WebService).Path("/").Scopes("read")
+	ws.Route(ws.GET("/a").To(dummy))
+	ws.Route(ws.GET("/b").Filter(noop).Roles("admin").Scopes("write").To(dummy))
+	a, b := ws.Routes()[0], ws.Routes()[1]
+	if len(a.Scopes) != 1 || a.Scopes[0] != "read" || len(a.Filters) != 1 {
+		t.Errorf("unexpected a:%v %d", a.Scopes, len(a.Filters))
+	}
+	if b.Roles[0] != "admin" || b.Scopes[0] != "write" || len(b.Filters) != 2 {
+		t.Errorf("unexpected b:%v %v %d", b.Roles, b.Scopes, len(b.Filters))
+	}
+	if len(new(RouteBuilder).Path("/d").To(dummy).Build().Filters) != 0 {
+		t.Error("Route without requirements should not have the AuthorizationFilter")
+	}
+}
+
+func noop(req *Request, resp *Response, chain *FilterChain) {
+	chain.ProcessFilter(req, resp)
+}
@@ -22,7 +22,7 @@ func setupCurly(container *Container) {
 		}
 		container.Add(ws)
 		for _, each := range ws.Routes() {
-			uris = append(uris, "http://bench.com"+each.Path)
+			uris_curly = append(uris_curly, "http://bench.com"+each.Path)
 		}
 	}
 }
@@ -0,0 +1,74 @@
+package restful
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+var uris_trie = []string{}
+
+func setupTrie(container *Container) {
+	wsCount := 26
+	rtCount := 26
+
+	container.Router(new(TrieRouter))
+	for i := 0; i < wsCount; i++ {
+		root := fmt.Sprintf("/%c/{%c}/", i+97, i+97)
+		ws := new(WebService).Path(root)
+		for j := 0; j < rtCount; j++ {
+			sub := fmt.Sprintf("/%c2/{%c2}", j+97, j+97)
+			ws.Route(ws.GET(sub).To(echoTrie))
+		}
+		container.Add(ws)
+		for _, each := range ws.Routes() {
+			uris_trie = append(uris_trie, "http://bench.com"+each.Path)
+		}
+	}
+}
+
+func echoTrie(req *Request, resp *Response) {
+	io.WriteString(resp.ResponseWriter, "echo")
+}
+
+func BenchmarkManyTrie(b *testing.B) {
+	container := NewContainer()
+	setupTrie(container)
+	b.ResetTimer()
+	for t := 0; t < b.N; t++ {
+		for _, each := range uris_trie {
+			// println(each)
+			sendItTo(each, container)
+		}
+	}
+}
+
+// go test -test.run none -test.bench SelectRoute ...restful
+func benchmarkSelectRoute(router RouteSelector, b *testing.B) {
+	container := NewContainer()
+	container.Router(router)
+	requests := []*http.Request{}
+	for i := 0; i < 26; i++ {
+		ws := new(WebService).Path(fmt.Sprintf("/%c/{%c}/", i+97, i+97))
+		for j := 0; j < 26; j++ {
+			ws.Route(ws.GET(fmt.Sprintf("/%c2/{%c2}", j+97, j+97)).To(echoTrie))
+		}
+		container.Add(ws)
+		for _, each := range ws.Routes() {
+			httpRequest, _ := http.NewRequest("GET", "http://bench.com"+each.Path, nil)
+			httpRequest.Header.Set("Accept", "*/*")
+			requests = append(requests, httpRequest)
+		}
+	}
+	b.ResetTimer()
+	for t := 0; t < b.N; t++ {
+		for _, each := range requests {
+			router.SelectRoute(container.webServices, each)
+		}
+	}
+}
+
+func BenchmarkSelectRouteJSR311(b *testing.B) { benchmarkSelectRoute(RouterJSR311{}, b) }
+func BenchmarkSelectRouteCurly(b *testing.B)  { benchmarkSelectRoute(CurlyRouter{}, b) }
+func BenchmarkSelectRouteTrie(b *testing.B)   { benchmarkSelectRoute(new(TrieRouter), b) }
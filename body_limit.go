@@ -0,0 +1,94 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"io"
+	"net/http"
+)
+
+// errBodyTooLarge is the ServiceError for requests with a body larger than the maximum size.
+// It is also returned by ReadEntity if the limit is exceeded while reading.
+var errBodyTooLarge = NewError(http.StatusRequestEntityTooLarge, "413: Request Entity Too Large")
+
+// MaxBodySize sets the maximum number of bytes of the request body for each Route of the WebService.
+// A Route can override it using RouteBuilder.MaxBodySize.
+func (w *WebService) MaxBodySize(bytes int64) *WebService {
+	w.maxBodySize = bytes
+	return w
+}
+
+// MaxBodySize sets the maximum number of bytes of the request body for each Route of all WebServices.
+// A WebService or Route can override it. A request with a larger Content-Length is answered with a 413 ServiceError
+// (using the ServiceErrorHandler, after the Container filters) without calling the other filters and the function
+// of the Route. If the length is unknown
+// (chunked) then reading beyond the maximum fails and ReadEntity returns a ServiceError with code 413.
+func (c *Container) MaxBodySize(bytes int64) {
+	c.maxBodySize = bytes
+}
+
+// maxBodySizeOf returns the maximum body size of the Route, WebService or Container, whichever is set first.
+func (c *Container) maxBodySizeOf(webService *WebService, route *Route) int64 {
+	if route.MaxBodySize > 0 {
+		return route.MaxBodySize
+	}
+	if webService.maxBodySize > 0 {
+		return webService.maxBodySize
+	}
+	return c.maxBodySize
+}
+
+// limitBody replaces the body of the request by one that fails when reading more than limit bytes.
+// It returns false if the Content-Length of the request exceeds the limit ; then the caller must write errBodyTooLarge.
+func (c *Container) limitBody(limit int64, req *Request, resp *Response) bool {
+	if req.Request.Body == nil || req.Request.Body == http.NoBody {
+		return true
+	}
+	if req.Request.ContentLength > limit {
+		resp.Header().Set("Connection", "close")
+		return false
+	}
+	req.Request.Body = &maxBodyReader{body: req.Request.Body, remaining: limit, header: resp.Header()}
+	return true
+}
+
+// maxBodyReader is a request body that returns errBodyTooLarge when reading more than remaining bytes.
+type maxBodyReader struct {
+	body      io.ReadCloser
+	remaining int64
+	exceeded  bool
+	header    http.Header // if not nil then Connection: close is set when exceeded ; the rest of the body is not read
+}
+
+// Read is part of io.Reader
+func (m *maxBodyReader) Read(p []byte) (int, error) {
+	if m.exceeded {
+		return 0, errBodyTooLarge
+	}
+	if int64(len(p)) > m.remaining+1 {
+		p = p[:m.remaining+1] // one more to detect exceeding
+	}
+	n, err := m.body.Read(p)
+	if int64(n) > m.remaining {
+		n, m.remaining, m.exceeded = int(m.remaining), 0, true
+		if m.header != nil {
+			m.header.Set("Connection", "close")
+		}
+		return n, errBodyTooLarge
+	}
+	m.remaining -= int64(n)
+	return n, err
+}
+
+// Close is part of io.Closer
+func (m *maxBodyReader) Close() error {
+	return m.body.Close()
+}
+
+// bodyTooLarge returns whether reading the body of the request failed because it exceeded the maximum size.
+func bodyTooLarge(httpRequest *http.Request) bool {
+	limited, ok := httpRequest.Body.(*maxBodyReader)
+	return ok && limited.exceeded
+}
@@ -0,0 +1,95 @@
+package restful
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// go test -v -test.run TestMaxBodySize ...restful
+func TestMaxBodySize(t *testing.T) {
+	container := NewContainer()
+	container.MaxBodySize(1024)
+	ws := new(WebService).Path("/samples").MaxBodySize(16)
+	read := func(req *Request, resp *Response) {
+		sam := new(Sample)
+		if err := req.ReadEntity(sam); err != nil {
+			if serviceError, ok := err.(ServiceError); ok {
+				resp.WriteServiceError(serviceError.Code, serviceError)
+				return
+			}
+			resp.WriteErrorString(http.StatusBadRequest, err.Error())
+			return
+		}
+		io.WriteString(resp, sam.Value)
+	}
+	ws.Route(ws.POST("").To(read))
+	ws.Route(ws.PUT("").MaxBodySize(64).To(read))
+	container.Add(ws)
+
+	for i, each := range []struct {
+		method, body string
+		chunked      bool
+		status       int
+	}{
+		{"POST", `{"Value":"42"}`, false, 200},
+		{"POST", `{"Value":"4242424242"}`, false, 413},
+		{"POST", `{"Value":"4242424242"}`, true, 413},
+		{"PUT", `{"Value":"4242424242"}`, false, 200},
+		{"PUT", `{"Value":"4242424242"}`, true, 200},
+	} {
+		httpRequest, _ := http.NewRequest(each.method, "http://here.com/samples", strings.NewReader(each.body))
+		if each.chunked {
+			httpRequest.ContentLength = -1
+		}
+		httpRequest.Header.Set(HEADER_ContentType, MIME_JSON)
+		httpRequest.Header.Set(HEADER_Accept, MIME_JSON)
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		if httpWriter.Code != each.status {
+			t.Errorf("%d: expected %d got %d %s", i, each.status, httpWriter.Code, httpWriter.Body.String())
+		}
+		if each.status == 413 && httpWriter.Header().Get("Connection") != "close" {
+			t.Errorf("%d: expected the connection to be closed", i)
+		}
+	}
+}
+
+func TestMaxBodySizeUsesServiceErrorHandler(t *testing.T) {
+	container := NewContainer()
+	container.MaxBodySize(4)
+	container.ServiceErrorHandler(WriteProblemDetails)
+	container.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		resp.AddHeader("X-Container-Filter", "true")
+		chain.ProcessFilter(req, resp)
+	})
+	ws := new(WebService).Path("/samples")
+	ws.Route(ws.POST("").Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		t.Error("route filter should not be called")
+	}).To(dummy))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("POST", "http://here.com/samples", strings.NewReader(`{"Value":"42"}`))
+	httpRequest.Header.Set(HEADER_ContentType, MIME_JSON)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != 413 || httpWriter.Header().Get(HEADER_ContentType) != MIME_ProblemJSON {
+		t.Errorf("expected problem details, got:%d %v", httpWriter.Code, httpWriter.Header())
+	}
+	if httpWriter.Header().Get("X-Container-Filter") != "true" {
+		t.Error("expected the container filter to be called")
+	}
+}
+
+func TestMaxBodyReader(t *testing.T) {
+	limited := &maxBodyReader{body: io.NopCloser(strings.NewReader("0123456789")), remaining: 10}
+	if data, err := io.ReadAll(limited); err != nil || len(data) != 10 {
+		t.Errorf("body of exactly the limit should be readable:%q %v", data, err)
+	}
+	limited = &maxBodyReader{body: io.NopCloser(strings.NewReader("0123456789")), remaining: 9}
+	if data, err := io.ReadAll(limited); err != errBodyTooLarge || len(data) != 9 || !limited.exceeded {
+		t.Errorf("expected errBodyTooLarge after 9 bytes:%q %v", data, err)
+	}
+}
@@ -5,10 +5,12 @@ package restful
 // that can be found in the LICENSE file.
 
 import (
+	"bufio"
 	"compress/gzip"
 	"compress/zlib"
 	"errors"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 )
@@ -20,6 +22,7 @@ var EnableContentEncoding = false
 type CompressingResponseWriter struct {
 	writer     http.ResponseWriter
 	compressor io.WriteCloser
+	closed     bool
 }
 
 // Header is part of http.ResponseWriter interface
@@ -38,11 +41,46 @@ func (c *CompressingResponseWriter) Write(bytes []byte) (int, error) {
 	return c.compressor.Write(bytes)
 }
 
-// Close the underlying compressor
+// Close the underlying compressor ; closing again does nothing.
 func (c *CompressingResponseWriter) Close() {
+	if c.closed {
+		return
+	}
+	c.closed = true
 	c.compressor.Close()
 }
 
+// Flush is part of http.Flusher interface
+// It writes the data buffered by the compressor and then flushes the underlying writer, if it supports it.
+func (c *CompressingResponseWriter) Flush() {
+	if flusher, ok := c.compressor.(interface {
+		Flush() error
+	}); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := c.writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// CloseNotify is part of http.CloseNotifier interface
+// If the underlying writer does not support it then the returned channel never receives.
+func (c *CompressingResponseWriter) CloseNotify() <-chan bool {
+	if notifier, ok := c.writer.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+// Hijack is part of http.Hijacker interface
+// The connection is taken over from the underlying writer ; data buffered by the compressor is discarded.
+func (c *CompressingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := c.writer.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.New("[restful] ResponseWriter does not implement http.Hijacker")
+}
+
 // WantsCompressedResponse reads the Accept-Encoding header to see if and which encoding is requested.
 func wantsCompressedResponse(httpRequest *http.Request) (bool, string) {
 	header := httpRequest.Header.Get(HEADER_AcceptEncoding)
@@ -0,0 +1,149 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SetETag sets the ETag header. The tag is quoted unless it already is, e.g. "v1" or W/"v1" (weak).
+func (r *Response) SetETag(etag string) {
+	r.Header().Set(HEADER_ETag, quotedETag(etag))
+}
+
+// SetLastModified sets the Last-Modified header using the HTTP date format (in UTC).
+func (r *Response) SetLastModified(modified time.Time) {
+	r.Header().Set(HEADER_LastModified, modified.UTC().Format(http.TimeFormat))
+}
+
+// CheckPreconditions evaluates the conditional headers of the request (If-Match, If-Unmodified-Since,
+// If-None-Match and If-Modified-Since) against the current ETag and modification time of the resource.
+// A zero lastModified (or empty etag) means unknown ; the resource must exist such that * matches it.
+// It returns true if the request must be processed ; otherwise it has written 304 (Not Modified), with the ETag
+// and Last-Modified headers, for GET and HEAD or 412 (Precondition Failed).
+// Use it before changing a resource to get optimistic concurrency:
+//
+//	order := findOrder(req.PathParameter("id"))
+//	if !resp.CheckPreconditions(order.Version, order.Modified) {
+//		return
+//	}
+func (r *Response) CheckPreconditions(etag string, lastModified time.Time) bool {
+	if r.request == nil {
+		return true
+	}
+	if etag != "" {
+		etag = quotedETag(etag)
+	}
+	status := evaluatePreconditions(r.request, etag, lastModified)
+	if status == 0 {
+		return true
+	}
+	if status == http.StatusNotModified {
+		if etag != "" {
+			r.Header().Set(HEADER_ETag, etag)
+		}
+		if !lastModified.IsZero() {
+			r.SetLastModified(lastModified)
+		}
+		r.WriteHeader(status)
+		return false
+	}
+	r.writeServiceError(NewError(status, "412: Precondition Failed"))
+	return false
+}
+
+// checkEntityPreconditions sets a strong ETag computed from the encoded entity (unless one is set) and evaluates
+// the conditional headers of a GET or HEAD request. It returns true if the entity must be written.
+// The content encoding (e.g. gzip) is appended to the computed ETag because each encoding is a different representation.
+func (r *Response) checkEntityPreconditions(entity []byte) bool {
+	if r.request == nil || (r.request.Method != "GET" && r.request.Method != "HEAD") {
+		return true
+	}
+	etag := r.Header().Get(HEADER_ETag)
+	if etag == "" {
+		sum := sha256.Sum256(entity)
+		opaque := hex.EncodeToString(sum[:16])
+		if r.contentEncoding != "" {
+			opaque += "-" + r.contentEncoding
+		}
+		etag = `"` + opaque + `"`
+		r.Header().Set(HEADER_ETag, etag)
+	}
+	lastModified, _ := http.ParseTime(r.Header().Get(HEADER_LastModified))
+	status := evaluatePreconditions(r.request, etag, lastModified)
+	if status == 0 {
+		return true
+	}
+	if status == http.StatusPreconditionFailed {
+		r.writeServiceError(NewError(status, "412: Precondition Failed"))
+		return false
+	}
+	r.WriteHeader(status)
+	return false
+}
+
+// evaluatePreconditions returns 0 if the request must be processed, otherwise 304 or 412.
+// The order of evaluation is that of RFC 7232, section 6.
+func evaluatePreconditions(httpRequest *http.Request, etag string, lastModified time.Time) int {
+	lastModified = lastModified.Truncate(time.Second) // HTTP dates have no fractions
+	isGetOrHead := httpRequest.Method == "GET" || httpRequest.Method == "HEAD"
+	if ifMatch := httpRequest.Header.Get(HEADER_IfMatch); ifMatch != "" {
+		if !etagListMatches(ifMatch, etag, true) {
+			return http.StatusPreconditionFailed
+		}
+	} else if since, err := http.ParseTime(httpRequest.Header.Get(HEADER_IfUnmodifiedSince)); err == nil && !lastModified.IsZero() {
+		if lastModified.After(since) {
+			return http.StatusPreconditionFailed
+		}
+	}
+	if ifNoneMatch := httpRequest.Header.Get(HEADER_IfNoneMatch); ifNoneMatch != "" {
+		if etagListMatches(ifNoneMatch, etag, false) {
+			if isGetOrHead {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if since, err := http.ParseTime(httpRequest.Header.Get(HEADER_IfModifiedSince)); err == nil && isGetOrHead && !lastModified.IsZero() {
+		if !lastModified.After(since) {
+			return http.StatusNotModified
+		}
+	}
+	return 0
+}
+
+// etagListMatches returns whether the etag matches any of the comma separated list (or *) of a conditional header.
+// A * matches any (existing) representation, also one without etag.
+// Strong comparison requires both tags to be strong ; weak comparison ignores the W/ prefix.
+func etagListMatches(list, etag string, strong bool) bool {
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	if etag == "" {
+		return false
+	}
+	for _, each := range strings.Split(list, ",") {
+		each = strings.TrimSpace(each)
+		if strong {
+			if each == etag && !strings.HasPrefix(etag, "W/") {
+				return true
+			}
+		} else if strings.TrimPrefix(each, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// quotedETag returns the etag with quotes, e.g. abc becomes "abc" ; quoted and weak tags are returned as is.
+func quotedETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
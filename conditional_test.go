@@ -0,0 +1,184 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEvaluatePreconditions(t *testing.T) {
+	modified := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	before, after := modified.Add(-time.Hour).Format(http.TimeFormat), modified.Add(time.Hour).Format(http.TimeFormat)
+	for i, each := range []struct {
+		method, header, value string
+		status                int
+	}{
+		{"GET", "", "", 0},
+		{"GET", HEADER_IfNoneMatch, `"v1"`, 304},
+		{"GET", HEADER_IfNoneMatch, `W/"v1"`, 304},
+		{"GET", HEADER_IfNoneMatch, `"v0", "v2"`, 0},
+		{"GET", HEADER_IfNoneMatch, `*`, 304},
+		{"PUT", HEADER_IfNoneMatch, `*`, 412},
+		{"GET", HEADER_IfModifiedSince, after, 304},
+		{"GET", HEADER_IfModifiedSince, before, 0},
+		{"PUT", HEADER_IfModifiedSince, after, 0},
+		{"PUT", HEADER_IfMatch, `"v1"`, 0},
+		{"PUT", HEADER_IfMatch, `W/"v1"`, 412},
+		{"PUT", HEADER_IfMatch, `"v0"`, 412},
+		{"PUT", HEADER_IfMatch, `*`, 0},
+		{"PUT", HEADER_IfUnmodifiedSince, before, 412},
+		{"PUT", HEADER_IfUnmodifiedSince, after, 0},
+	} {
+		httpRequest, _ := http.NewRequest(each.method, "http://here.com/orders/1", nil)
+		if each.header != "" {
+			httpRequest.Header.Set(each.header, each.value)
+		}
+		if status := evaluatePreconditions(httpRequest, `"v1"`, modified.Add(time.Millisecond)); status != each.status {
+			t.Errorf("%d: %s %s=%s: expected %d got %d", i, each.method, each.header, each.value, each.status, status)
+		}
+	}
+	// * matches an existing resource without etag
+	httpRequest, _ := http.NewRequest("PUT", "http://here.com/orders/1", nil)
+	httpRequest.Header.Set(HEADER_IfMatch, "*")
+	if status := evaluatePreconditions(httpRequest, "", time.Time{}); status != 0 {
+		t.Errorf("If-Match * without etag: expected 0 got %d", status)
+	}
+}
+
+// go test -v -test.run TestCheckPreconditions ...restful
+func TestCheckPreconditions(t *testing.T) {
+	container := NewContainer()
+	container.ServiceErrorHandler(WriteProblemDetails)
+	ws := new(WebService).Path("/orders")
+	ws.Route(ws.PUT("/{id}").To(func(req *Request, resp *Response) {
+		if !resp.CheckPreconditions("v2", time.Time{}) {
+			return
+		}
+		resp.SetETag("v3")
+		resp.WriteHeader(http.StatusNoContent)
+	}))
+	container.Add(ws)
+
+	for ifMatch, status := range map[string]int{`"v2"`: 204, `"v1"`: 412} {
+		httpRequest, _ := http.NewRequest("PUT", "http://here.com/orders/1", nil)
+		httpRequest.Header.Set(HEADER_IfMatch, ifMatch)
+		httpRequest.Header.Set(HEADER_Accept, MIME_JSON)
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		if httpWriter.Code != status {
+			t.Errorf("If-Match %s: expected %d got %d", ifMatch, status, httpWriter.Code)
+		}
+		if status == 204 && httpWriter.Header().Get(HEADER_ETag) != `"v3"` {
+			t.Errorf("unexpected ETag:%s", httpWriter.Header().Get(HEADER_ETag))
+		}
+		if status == 412 && httpWriter.Header().Get(HEADER_ContentType) != MIME_ProblemJSON {
+			t.Errorf("expected problem details, got:%v", httpWriter.Header())
+		}
+	}
+}
+
+func TestCheckPreconditionsNotModified(t *testing.T) {
+	modified := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/orders/1", nil)
+	httpRequest.Header.Set(HEADER_IfNoneMatch, `"v2"`)
+	httpWriter := httptest.NewRecorder()
+	resp := newResponse(httpWriter)
+	resp.request = httpRequest
+	if resp.CheckPreconditions("v2", modified) {
+		t.Fatal("expected 304")
+	}
+	if httpWriter.Code != 304 || httpWriter.Header().Get(HEADER_ETag) != `"v2"` || httpWriter.Header().Get(HEADER_LastModified) != "Sun, 18 Oct 2026 12:00:00 GMT" {
+		t.Errorf("unexpected response:%d %v", httpWriter.Code, httpWriter.Header())
+	}
+}
+
+// go test -v -test.run TestEnableETagsWithContentEncoding ...restful
+func TestEnableETagsWithContentEncoding(t *testing.T) {
+	container := NewContainer()
+	container.EnableETags(true)
+	container.EnableContentEncoding(true)
+	container.Timeout(time.Minute)
+	ws := new(WebService).Path("/orders").Produces(MIME_JSON)
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		resp.WriteEntity(map[string]string{"id": req.PathParameter("id")})
+	}))
+	container.Add(ws)
+
+	request := func(method, encoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		httpRequest, _ := http.NewRequest(method, "http://here.com/orders/1", nil)
+		httpRequest.Header.Set(HEADER_AcceptEncoding, encoding)
+		httpRequest.Header.Set(HEADER_IfNoneMatch, ifNoneMatch)
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+	get := func(encoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		return request("GET", encoding, ifNoneMatch)
+	}
+	identity := get("identity", "").Header().Get(HEADER_ETag)
+	gzipped := get(ENCODING_GZIP, "").Header().Get(HEADER_ETag)
+	if identity == gzipped || gzipped != identity[:len(identity)-1]+`-gzip"` {
+		t.Errorf("expected different ETags per encoding, got %s and %s", identity, gzipped)
+	}
+	if w := get(ENCODING_GZIP, gzipped); w.Code != 304 {
+		t.Errorf("expected 304 for the gzip ETag, got %d", w.Code)
+	}
+	if w := get(ENCODING_GZIP, identity); w.Code != 200 {
+		t.Errorf("expected 200 for the identity ETag, got %d", w.Code)
+	}
+	if head := request("HEAD", ENCODING_GZIP, "").Header().Get(HEADER_ETag); head != gzipped {
+		t.Errorf("HEAD and GET should have the same ETag, got %s and %s", head, gzipped)
+	}
+	if w := request("HEAD", ENCODING_GZIP, gzipped); w.Code != 304 {
+		t.Errorf("expected 304 for HEAD with the gzip ETag, got %d", w.Code)
+	}
+	for _, encoding := range []string{"identity", ENCODING_GZIP} {
+		if vary := get(encoding, "").Header().Get(HEADER_Vary); vary != HEADER_AcceptEncoding {
+			t.Errorf("%s: expected Vary: Accept-Encoding, got %q", encoding, vary)
+		}
+	}
+}
+
+// go test -v -test.run TestEnableETags ...restful
+func TestEnableETags(t *testing.T) {
+	container := NewContainer()
+	container.EnableETags(true)
+	container.ServiceErrorHandler(WriteProblemDetails)
+	ws := new(WebService).Path("/orders").Produces(MIME_JSON)
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		resp.WriteEntity(map[string]string{"id": req.PathParameter("id")})
+	}))
+	ws.Route(ws.GET("/{id}/lines").To(func(req *Request, resp *Response) {
+		resp.SetLastModified(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+		resp.WriteEntity([]string{})
+	}))
+	container.Add(ws)
+
+	get := func(url, header, value string) *httptest.ResponseRecorder {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com"+url, nil)
+		if header != "" {
+			httpRequest.Header.Set(header, value)
+		}
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+	first := get("/orders/1", "", "")
+	etag := first.Header().Get(HEADER_ETag)
+	if first.Code != 200 || len(etag) != 34 || etag != get("/orders/1", "", "").Header().Get(HEADER_ETag) {
+		t.Fatalf("expected stable strong ETag, got %d %s", first.Code, etag)
+	}
+	if other := get("/orders/2", "", "").Header().Get(HEADER_ETag); other == etag {
+		t.Error("different entities should have different ETags")
+	}
+	if w := get("/orders/1", HEADER_IfNoneMatch, etag); w.Code != 304 || w.Body.Len() != 0 {
+		t.Errorf("expected 304 without body, got %d %q", w.Code, w.Body.String())
+	}
+	if w := get("/orders/1/lines", HEADER_IfModifiedSince, "Sun, 18 Oct 2026 12:00:00 GMT"); w.Code != 304 {
+		t.Errorf("expected 304 for Last-Modified, got %d", w.Code)
+	}
+	if w := get("/orders/1", HEADER_IfMatch, `"other"`); w.Code != 412 || w.Header().Get(HEADER_ContentType) != MIME_ProblemJSON {
+		t.Errorf("expected 412 with problem details, got %d %v", w.Code, w.Header())
+	}
+}
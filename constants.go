@@ -10,13 +10,32 @@ const (
 	MIME_XML  = "application/xml"  // Accept or Content-Type used in Consumes() and/or Produces()
 	MIME_JSON = "application/json" // Accept or Content-Type used in Consumes() and/or Produces()
 
+	MIME_ProblemJSON = "application/problem+json" // Content-Type of RFC 7807 problem details, see WriteProblemDetails
+	MIME_ProblemXML  = "application/problem+xml"  // Content-Type of RFC 7807 problem details, see WriteProblemDetails
+
+	MIME_MultipartForm  = "multipart/form-data"               // Content-Type of forms with file uploads, see Request.FormFile
+	MIME_URLEncodedForm = "application/x-www-form-urlencoded" // Content-Type of forms, see Request.FormParameter
+
+	MIME_NDJSON = "application/x-ndjson" // Content-Type of newline-delimited JSON, see Response.StreamNDJSON
+
+	MIME_EventStream = "text/event-stream" // Content-Type of Server-Sent Events, see Response.StartEventStream
+
+	MIME_PrometheusText = "text/plain; version=0.0.4; charset=utf-8" // Content-Type of the Prometheus text exposition format, see Metrics
+
 	HEADER_Allow                         = "Allow"
 	HEADER_Accept                        = "Accept"
 	HEADER_Origin                        = "Origin"
 	HEADER_ContentType                   = "Content-Type"
 	HEADER_LastModified                  = "Last-Modified"
+	HEADER_ETag                          = "ETag"
+	HEADER_IfMatch                       = "If-Match"
+	HEADER_IfNoneMatch                   = "If-None-Match"
+	HEADER_IfModifiedSince               = "If-Modified-Since"
+	HEADER_IfUnmodifiedSince             = "If-Unmodified-Since"
+	HEADER_LastEventID                   = "Last-Event-ID"
 	HEADER_AcceptEncoding                = "Accept-Encoding"
 	HEADER_ContentEncoding               = "Content-Encoding"
+	HEADER_Vary                          = "Vary"
 	HEADER_AccessControlExposeHeaders    = "Access-Control-Expose-Headers"
 	HEADER_AccessControlRequestMethod    = "Access-Control-Request-Method"
 	HEADER_AccessControlRequestHeaders   = "Access-Control-Request-Headers"
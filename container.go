@@ -6,12 +6,14 @@ package restful
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	//"github.com/emicklei/hopwatch"
 	"log"
 	"net/http"
 	"runtime"
 	"strings"
+	"time"
 )
 
 // Container holds a collection of WebServices and a http.ServeMux to dispatch http requests.
@@ -25,6 +27,14 @@ type Container struct {
 	recoverHandleFunc      RecoverHandleFunction
 	router                 RouteSelector // default is a RouterJSR311
 	contentEncodingEnabled bool          // default is false
+	entityAccessors        *entityAccessRegistry
+	serviceErrorHandleFunc ServiceErrorHandleFunction
+	lifecycle              *containerLifecycle
+	timeout                time.Duration // zero means no timeout
+	timeoutStatus          int           // default is 503
+	etagsEnabled           bool          // default is false
+	maxBodySize            int64         // zero means unlimited
+	multipartMemory        int64         // zero means defaultMultipartMemory
 }
 
 // NewContainer creates a new Container using a new ServeMux and default router (RouterJSR311)
@@ -37,7 +47,11 @@ func NewContainer() *Container {
 		doNotRecover:           false,
 		recoverHandleFunc:      logStackOnRecover,
 		router:                 RouterJSR311{},
-		contentEncodingEnabled: false}
+		contentEncodingEnabled: false,
+		entityAccessors:        newEntityAccessRegistry(defaultEntityAccessors),
+		serviceErrorHandleFunc: writeServiceError,
+		lifecycle:              newContainerLifecycle(),
+		timeoutStatus:          http.StatusServiceUnavailable}
 }
 
 // RecoverHandleFunction declares functions that can be used to handle a panic situation.
@@ -50,6 +64,17 @@ func (c *Container) RecoverHandler(handler RecoverHandleFunction) {
 	c.recoverHandleFunc = handler
 }
 
+// ServiceErrorHandleFunction declares functions that can be used to handle a service error situation.
+// The first argument is the service error, the second is the request that resulted in the error and
+// the third must be used to communicate an error response.
+type ServiceErrorHandleFunction func(ServiceError, *Request, *Response)
+
+// ServiceErrorHandler changes the default function (writeServiceError) to be called
+// when a ServiceError is detected while routing a request (e.g. 404, 405, 406 or 415).
+func (c *Container) ServiceErrorHandler(handler ServiceErrorHandleFunction) {
+	c.serviceErrorHandleFunc = handler
+}
+
 // DoNotRecover controls whether panics will be caught to return HTTP 500.
 // If set to true, Route functions are responsible for handling any error situation.
 // Default value is false = recover from panics. This has performance implications.
@@ -60,13 +85,36 @@ func (c *Container) DoNotRecover(doNot bool) {
 // Router changes the default Router (currently RouterJSR311)
 func (c *Container) Router(aRouter RouteSelector) {
 	c.router = aRouter
+	c.invalidateRoutes()
+}
+
+// invalidateRoutes lets the router discard what it prepared for the previous set of Routes, if anything.
+func (c *Container) invalidateRoutes() {
+	if compiler, ok := c.router.(routeCompiler); ok {
+		compiler.invalidateRoutes()
+	}
 }
 
 // EnableContentEncoding (default=false) allows for GZIP or DEFLATE encoding of responses.
+// Responses then have the header Vary: Accept-Encoding.
 func (c *Container) EnableContentEncoding(enabled bool) {
 	c.contentEncodingEnabled = enabled
 }
 
+// EnableETags (default=false) allows for computing a strong ETag for entities written using WriteEntity
+// in response to GET and HEAD requests, and for answering conditional requests with 304 or 412 based on it.
+// If the response is compressed (see EnableContentEncoding) then the encoding is part of the ETag.
+// An ETag or Last-Modified header set by the RouteFunction (see SetETag and SetLastModified) is used as is.
+func (c *Container) EnableETags(enabled bool) {
+	c.etagsEnabled = enabled
+}
+
+// RegisterEntityAccessor adds or replaces the EntityReaderWriter for a MIME type for this Container only.
+// MIME types not registered on the Container are looked up in the package default registry (see RegisterEntityAccessor).
+func (c *Container) RegisterEntityAccessor(mimeType string, erw EntityReaderWriter) {
+	c.entityAccessors.register(mimeType, erw)
+}
+
 // Add a WebService to the Container. It will detect duplicate root paths and panic in that case.
 func (c *Container) Add(service *WebService) *Container {
 	if service.pathExpr == nil {
@@ -102,7 +150,10 @@ func (c *Container) Add(service *WebService) *Container {
 			log.Fatalf("[restful] WebService with duplicate root path detected:['%v']", each)
 		}
 	}
+	service.useEntityAccessors(c.entityAccessors)
+	service.routesChanged = c.invalidateRoutes
 	c.webServices = append(c.webServices, service)
+	c.invalidateRoutes()
 	return c
 }
 
@@ -125,8 +176,43 @@ func logStackOnRecover(panicReason interface{}, httpWriter http.ResponseWriter)
 	httpWriter.Write(buffer.Bytes())
 }
 
+// writeServiceError is the default ServiceErrorHandleFunction and is called
+// when a ServiceError is detected and the serviceErrorHandleFunc is not set for the container.
+// Default implementation writes the ServiceError as an entity using content negotiation ;
+// if no registered MIME type is acceptable then it is written as JSON.
+func writeServiceError(serviceError ServiceError, req *Request, resp *Response) {
+	registry := resp.accessors.orDefault()
+	mimeType, ok := negotiatedMimeType(resp.accept, registry.registeredMimeTypes(), registry)
+	if !ok {
+		// a representation that is not acceptable is better than none
+		mimeType = MIME_JSON
+	}
+	if erw, ok := registry.accessorAt(mimeType); ok {
+		resp.writeEntityWith(serviceError.Code, mimeType, erw, serviceError)
+		return
+	}
+	resp.WriteErrorString(serviceError.Code, serviceError.Message)
+}
+
 // Dispatch the incoming Http Request to a matching WebService.
 func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.Request) {
+	// Refuse new requests when shutting down
+	if !c.lifecycle.enter() {
+		httpWriter.Header().Set("Connection", "close")
+		c.handleServiceError(errShuttingDown, httpWriter, httpRequest)
+		return
+	}
+	// Release the dispatch when done, unless the function of a timed out Route is still running
+	abandoned := false
+	defer func() {
+		if !abandoned {
+			c.releaseDispatch(httpRequest)
+		}
+	}()
+	// Derive a context that is cancelled when the client goes away or when the dispatch is done
+	ctx, cancel := context.WithCancel(httpRequest.Context())
+	defer cancel()
+	httpRequest = httpRequest.WithContext(ctx)
 	// Instal panic recovery unless told otherwise
 	if !c.doNotRecover { // catch all for 500 response
 		defer func() {
@@ -136,55 +222,108 @@ func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.R
 			}
 		}()
 	}
-	// Install closing the request body (if any)
-	defer func() {
-		if nil != httpRequest.Body {
-			httpRequest.Body.Close()
-		}
-	}()
+	// Find best match Route ; err is non nil if no match was found
+	webService, route, err := c.router.SelectRoute(
+		c.webServices,
+		httpRequest)
 
+	writer := httpWriter
+	var head *headResponseWriter
+	if err == nil && httpRequest.Method == "HEAD" {
+		// the Route may be a GET Route ; discard the body but keep the headers and length of GET
+		head = &headResponseWriter{ResponseWriter: httpWriter}
+		writer = head
+	}
 	// Detect if compression is needed
 	// assume without compression, test for override
-	writer := httpWriter
-	if c.contentEncodingEnabled {
-		doCompress, encoding := wantsCompressedResponse(httpRequest)
-		if doCompress {
+	var compressing *CompressingResponseWriter
+	if c.contentEncodingEnabled && (err != nil || !route.WebSocket) {
+		// the representation depends on the Accept-Encoding, also if it is not compressed
+		httpWriter.Header().Add(HEADER_Vary, HEADER_AcceptEncoding)
+		if encoding := c.contentEncodingFor(route, httpRequest); encoding != "" {
 			var err error
-			writer, err = NewCompressingResponseWriter(httpWriter, encoding)
+			compressing, err = NewCompressingResponseWriter(writer, encoding)
 			if err != nil {
 				log.Println("[restful] unable to install compressor:", err)
 				httpWriter.WriteHeader(http.StatusInternalServerError)
 				return
 			}
-			defer func() {
-				writer.(*CompressingResponseWriter).Close()
-			}()
+			writer = compressing
+			defer compressing.Close()
 		}
 	}
-	// Find best match Route ; err is non nil if no match was found
-	webService, route, err := c.router.SelectRoute(
-		c.webServices,
-		httpRequest)
 	if err != nil {
-		// a non-200 response has already been written
-		// run container filters anyway ; they should not touch the response...
-		chain := FilterChain{Filters: c.containerFilters, Target: func(req *Request, resp *Response) {
-			switch err.(type) {
-			case ServiceError:
-				ser := err.(ServiceError)
-				httpWriter.WriteHeader(ser.Code)
-				httpWriter.Write([]byte(ser.Message))
-			}
+		// no response has been written yet
+		serviceError, ok := err.(ServiceError)
+		if !ok {
+			serviceError = NewError(http.StatusNotFound, "404: Page Not Found")
+		}
+		c.handleServiceError(serviceError, writer, httpRequest)
+		return
+	}
+	if head != nil {
+		abandoned = c.dispatchSelected(webService, route, writer, httpRequest)
+		if compressing != nil {
+			// the length includes the end of the compressed stream
+			compressing.Close()
+		}
+		head.finish()
+		return
+	}
+	abandoned = c.dispatchSelected(webService, route, writer, httpRequest)
+}
 
-			// err
+// releaseDispatch closes the request body (if any) and unregisters the dispatch that was accepted by the lifecycle.
+func (c *Container) releaseDispatch(httpRequest *http.Request) {
+	if nil != httpRequest.Body {
+		httpRequest.Body.Close()
+	}
+	c.lifecycle.leave()
+}
 
-			// handle err here
+// contentEncodingFor returns the encoding (gzip or deflate) negotiated for the response ; empty if not compressed.
+// The route is nil if none was selected.
+func (c *Container) contentEncodingFor(route *Route, httpRequest *http.Request) string {
+	if !c.contentEncodingEnabled || (route != nil && route.WebSocket) {
+		return ""
+	}
+	if doCompress, encoding := wantsCompressedResponse(httpRequest); doCompress {
+		return encoding
+	}
+	return ""
+}
+
+// dispatchSelected dispatches to the Route, with a timeout if one applies.
+// It returns true if the function of the Route is still running after a timeout ; then that releases the dispatch.
+func (c *Container) dispatchSelected(webService *WebService, route *Route, writer http.ResponseWriter, httpRequest *http.Request) bool {
+	// a WebSocket connection outlives the request
+	if timeout := c.timeoutOf(webService, route); timeout > 0 && !route.WebSocket {
+		return c.dispatchWithTimeout(timeout, webService, route, writer, httpRequest)
+	}
+	wrappedRequest, wrappedResponse := route.wrapRequestResponse(writer, httpRequest)
+	c.dispatchToRoute(webService, route, wrappedRequest, wrappedResponse)
+	return false
+}
 
+// dispatchToRoute passes the request through all filters (if any) and then calls the function of the Route.
+func (c *Container) dispatchToRoute(webService *WebService, route *Route, wrappedRequest *Request, wrappedResponse *Response) {
+	wrappedResponse.computeETag = c.etagsEnabled
+	wrappedResponse.contentEncoding = c.contentEncodingFor(route, wrappedRequest.Request)
+	wrappedResponse.serviceErrorHandler = func(serviceError ServiceError) {
+		c.serviceErrorHandleFunc(serviceError, wrappedRequest, wrappedResponse)
+	}
+	wrappedRequest.multipartMemory = c.multipartMemory
+	defer func() {
+		removeMultipartFiles(wrappedRequest.Request)
+	}()
+	if limit := c.maxBodySizeOf(webService, route); limit > 0 && !c.limitBody(limit, wrappedRequest, wrappedResponse) {
+		// run container filters anyway, as for the ServiceErrors of handleServiceError
+		chain := FilterChain{Filters: c.containerFilters, Target: func(req *Request, resp *Response) {
+			resp.writeServiceError(errBodyTooLarge)
 		}}
-		chain.ProcessFilter(newRequest(httpRequest), newResponse(writer))
+		chain.ProcessFilter(wrappedRequest, wrappedResponse)
 		return
 	}
-	wrappedRequest, wrappedResponse := route.wrapRequestResponse(writer, httpRequest)
 	// pass through filters (if any)
 	if len(c.containerFilters)+len(webService.filters)+len(route.Filters) > 0 {
 		// compose filter chain
@@ -203,6 +342,21 @@ func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.R
 	}
 }
 
+// handleServiceError passes the request through the container filters and then writes the ServiceError
+// using the ServiceErrorHandleFunction. No response must have been written yet.
+func (c *Container) handleServiceError(serviceError ServiceError, httpWriter http.ResponseWriter, httpRequest *http.Request) {
+	errorRequest, errorResponse := newBasicRequestResponse(httpWriter, httpRequest)
+	errorRequest.accessors, errorResponse.accessors = c.entityAccessors, c.entityAccessors
+	if serviceError.Code == http.StatusMethodNotAllowed {
+		errorResponse.Header().Set(HEADER_Allow, toCommaSeparated(c.computeAllowedMethods(errorRequest)))
+	}
+	// run container filters anyway ; they should not touch the response...
+	chain := FilterChain{Filters: c.containerFilters, Target: func(req *Request, resp *Response) {
+		c.serviceErrorHandleFunc(serviceError, req, resp)
+	}}
+	chain.ProcessFilter(errorRequest, errorResponse)
+}
+
 // fixedPrefixPath returns the fixed part of the partspec ; it may include template vars {}
 func (c Container) fixedPrefixPath(pathspec string) string {
 	varBegin := strings.Index(pathspec, "{")
@@ -233,7 +387,8 @@ func (c Container) RegisteredWebServices() []*WebService {
 	return c.webServices
 }
 
-// computeAllowedMethods returns a list of HTTP methods that are valid for a Request
+// computeAllowedMethods returns a list of HTTP methods that are valid for a Request, without duplicates.
+// HEAD is included whenever GET is because a GET Route also answers HEAD requests.
 func (c Container) computeAllowedMethods(req *Request) []string {
 	// Go through all RegisteredWebServices() and all its Routes to collect the options
 	methods := []string{}
@@ -246,13 +401,16 @@ func (c Container) computeAllowedMethods(req *Request) []string {
 				matches := rt.pathExpr.Matcher.FindStringSubmatch(finalMatch)
 				if matches != nil {
 					lastMatch := matches[len(matches)-1]
-					if lastMatch == "" || lastMatch == "/" { // do not include if value is neither empty nor ‘/’.
+					if (lastMatch == "" || lastMatch == "/") && !containsString(methods, rt.Method) { // do not include if value is neither empty nor ‘/’.
 						methods = append(methods, rt.Method)
 					}
 				}
 			}
 		}
 	}
+	if containsString(methods, "GET") && !containsString(methods, "HEAD") {
+		methods = append(methods, "HEAD")
+	}
 	// methods = append(methods, "OPTIONS")  not sure about this
 	return methods
 }
@@ -0,0 +1,60 @@
+package restful
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type contextKey string
+
+// go test -v -test.run TestRequestContext ...restful
+func TestRequestContext(t *testing.T) {
+	container := NewContainer()
+	var dispatched context.Context
+	ws := new(WebService).Path("/ctx")
+	ws.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		req.SetContext(context.WithValue(req.Context(), contextKey("user"), "ernest"))
+		chain.ProcessFilter(req, resp)
+	})
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		dispatched = req.Context()
+		if dispatched.Err() != nil {
+			t.Error("context should not be done while dispatching")
+		}
+		resp.Write([]byte(req.Context().Value(contextKey("user")).(string)))
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/ctx", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Body.String() != "ernest" {
+		t.Errorf("unexpected body:%s", httpWriter.Body.String())
+	}
+	if dispatched.Err() != context.Canceled {
+		t.Errorf("context should be cancelled after dispatch, got:%v", dispatched.Err())
+	}
+}
+
+// go test -v -test.run TestRequestContextClientGone ...restful
+func TestRequestContextClientGone(t *testing.T) {
+	container := NewContainer()
+	ws := new(WebService).Path("/ctx")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		<-req.Context().Done()
+		resp.WriteHeader(499)
+	}))
+	container.Add(ws)
+
+	clientCtx, disconnect := context.WithCancel(context.Background())
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/ctx", nil)
+	httpRequest = httpRequest.WithContext(clientCtx)
+	disconnect()
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != 499 {
+		t.Errorf("unexpected status:%d", httpWriter.Code)
+	}
+}
@@ -6,7 +6,6 @@ package restful
 
 import (
 	//	"log"
-	"errors"
 	"net/http"
 	"sort"
 	"strings"
@@ -25,11 +24,11 @@ func (c CurlyRouter) SelectRoute(
 
 	detectedService := c.detectWebService(requestTokens, webServices)
 	if detectedService == nil {
-		return nil, nil, errors.New("no detected service")
+		return nil, nil, NewError(http.StatusNotFound, "404: Page Not Found")
 	}
 	candidateRoutes := c.selectRoutes(detectedService, requestTokens)
 	if len(candidateRoutes) == 0 {
-		return detectedService, nil, errors.New("no candidate routes")
+		return detectedService, nil, NewError(http.StatusNotFound, "404: Page Not Found")
 	}
 	selectedRoute, err := c.detectRoute(candidateRoutes, httpRequest)
 	if selectedRoute == nil {
@@ -42,8 +41,8 @@ func (c CurlyRouter) selectRoutes(ws *WebService, requestTokens []string) []Rout
 	candidates := &sortableCurlyRoutes{[]*curlyRoute{}}
 	for _, each := range ws.routes {
 		matches, paramCount, staticCount := c.matchesRouteByPathTokens(each.pathParts, requestTokens)
-		if matches {
-			candidates.add(&curlyRoute{each, paramCount, staticCount}) // TODO make sure Routes() return pointers?
+		if matches && matchesSegmentConstraints(each.pathMatchers, requestTokens) {
+			candidates.add(newCurlyRoute(each, paramCount, staticCount)) // TODO make sure Routes() return pointers?
 		}
 	}
 	sort.Sort(sort.Reverse(candidates))
@@ -52,9 +51,17 @@ func (c CurlyRouter) selectRoutes(ws *WebService, requestTokens []string) []Rout
 
 func (c CurlyRouter) matchesRouteByPathTokens(routeTokens, requestTokens []string) (matches bool, paramCount int, staticCount int) {
 	if len(routeTokens) != len(requestTokens) {
-		return false, 0, 0
+		// a catch-all parameter matches any remainder, including an empty one
+		catchAll := len(routeTokens) > 0 && isCatchAll(routeTokens[len(routeTokens)-1])
+		if !catchAll || len(routeTokens) > len(requestTokens)+1 {
+			return false, 0, 0
+		}
 	}
 	for i, routeToken := range routeTokens {
+		if i == len(requestTokens) || isCatchAll(routeToken) {
+			paramCount++
+			break
+		}
 		requestToken := requestTokens[i]
 		if !strings.HasPrefix(routeToken, "{") {
 			if requestToken != routeToken {
@@ -85,7 +92,7 @@ func (c CurlyRouter) detectWebService(requestTokens []string, webServices []*Web
 	score := -1
 	for _, each := range webServices {
 		matches, eachScore := c.computeWebserviceScore(requestTokens, each.pathExpr.tokens)
-		if matches && (eachScore > score) {
+		if matches && (eachScore > score) && matchesSegmentConstraints(each.pathExpr.segmentMatchers, requestTokens) {
 			best = each
 			score = eachScore
 		}
@@ -6,9 +6,22 @@ package restful
 
 // curlyRoute exits for sorting Routes by the CurlyRouter based on number of parameters and number of static path elements.
 type curlyRoute struct {
-	route       Route
-	paramCount  int
-	staticCount int
+	route           Route
+	paramCount      int
+	staticCount     int
+	nonDefaultCount int  // number of parameters with a regular expression constraint
+	catchAll        bool // whether the last parameter matches the remainder of the path
+}
+
+func newCurlyRoute(route Route, paramCount, staticCount int) *curlyRoute {
+	nonDefaultCount := 0
+	for _, each := range route.pathMatchers {
+		if each != nil {
+			nonDefaultCount++
+		}
+	}
+	catchAll := len(route.pathParts) > 0 && isCatchAll(route.pathParts[len(route.pathParts)-1])
+	return &curlyRoute{route, paramCount, staticCount, nonDefaultCount, catchAll}
 }
 
 type sortableCurlyRoutes struct {
@@ -43,6 +56,10 @@ func (s *sortableCurlyRoutes) Less(i, j int) bool {
 	if ci.staticCount > cj.staticCount {
 		return false
 	}
+	// a catch-all is the least specific
+	if ci.catchAll != cj.catchAll {
+		return ci.catchAll
+	}
 	// secundary key
 	if ci.paramCount < cj.paramCount {
 		return true
@@ -50,5 +67,12 @@ func (s *sortableCurlyRoutes) Less(i, j int) bool {
 	if ci.paramCount > cj.paramCount {
 		return false
 	}
+	// tertiary key
+	if ci.nonDefaultCount < cj.nonDefaultCount {
+		return true
+	}
+	if ci.nonDefaultCount > cj.nonDefaultCount {
+		return false
+	}
 	return ci.route.Path < cj.route.Path
 }
@@ -107,6 +107,9 @@ var routeMatchers = []struct {
 	{"/a", "/b", false, 0, 0},
 	{"/a/{b}/c/", "/a/2/c", true, 1, 2},
 	{"/{a}/{b}/{c}/", "/a/b", false, 0, 0},
+	{"/a/{b:*}", "/a/b/c", true, 1, 1},
+	{"/a/{b:*}", "/a", true, 1, 1},
+	{"/a/{b:*}", "/b/c", false, 0, 0},
 }
 
 // clear && go test -v -test.run Test_matchesRouteByPathTokens ...restful
@@ -172,3 +175,26 @@ func TestCurly_JsonHtml(t *testing.T) {
 }
 
 func curlyDummy(req *Request, resp *Response) { io.WriteString(resp.ResponseWriter, "curlyDummy") }
+
+// clear && go test -v -test.run TestCurly_Constraints ...restful
+func TestCurly_Constraints(t *testing.T) {
+	ws1 := new(WebService).Path("/")
+	ws1.Route(ws1.GET("/users/{name}").To(curlyDummy))
+	ws1.Route(ws1.GET("/users/{id:[0-9]+}").To(curlyDummy))
+	ws1.Route(ws1.GET("/users/{rest:*}").To(curlyDummy))
+	routes := CurlyRouter{}.selectRoutes(ws1, tokenizePath("/users/12"))
+	if len(routes) != 3 {
+		t.Fatalf("expected 3 routes, got:%v", routes)
+	}
+	if routes[0].Path != "/users/{id:[0-9]+}" || routes[2].Path != "/users/{rest:*}" {
+		t.Errorf("unexpected order:%v", routes)
+	}
+	routes = CurlyRouter{}.selectRoutes(ws1, tokenizePath("/users/ab"))
+	if len(routes) != 2 || routes[0].Path != "/users/{name}" {
+		t.Errorf("unexpected routes:%v", routes)
+	}
+	routes = CurlyRouter{}.selectRoutes(ws1, tokenizePath("/users/ab/cd"))
+	if len(routes) != 1 || routes[0].Path != "/users/{rest:*}" {
+		t.Errorf("unexpected routes:%v", routes)
+	}
+}
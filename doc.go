@@ -23,6 +23,13 @@ This package has the logic to find the best matching Route and if found, call it
 
 The (*Request, *Response) arguments provide functions for reading information from the request and writing information back to the response.
 
+Path parameters can be constrained by a regular expression, which must not contain a slash.
+A parameter with the * constraint matches the remainder of the path and must be the last in the path.
+If multiple Routes match a request path then a constrained parameter is preferred over an unconstrained one.
+
+	ws.Route(ws.GET("/{id:[0-9]+}").To(u.findUser))
+	ws.Route(ws.GET("/files/{path:*}").To(u.serveFile))
+
 Containers
 
 A Container holds a collection of WebServices, Filters and a http.ServeMux for multiplexing http requests.
@@ -82,6 +89,136 @@ If a Http request includes the Accept-Encoding header then the response content
 Alternatively, you can create a Filter that performs the encoding and install it per WebService or Route.
 See the example https://github.com/squishyent/go-restful/blob/master/examples/restful-encoding-filter.go
 
+Streaming
+
+Response implements http.Flusher, also if content encoding is enabled or a timeout applies. An EntityStream writes
+entities as newline-delimited JSON (StreamNDJSON) or as the elements of a JSON array (StreamJSONArray), flushing after each.
+
+	stream := resp.StreamNDJSON()
+	for each := range events {
+		if stream.Write(each) != nil {
+			return // the client went away
+		}
+	}
+
+Server-Sent Events
+
+StartEventStream turns a Response into a stream of Server-Sent Events (text/event-stream) ; Routes and filters apply as usual.
+Send writes id, event, retry and data fields, Heartbeat keeps idle connections alive and Done is closed when the client goes away.
+Request.LastEventID returns the id of the last event received by a reconnecting client.
+
+	stream := resp.StartEventStream()
+	defer stream.Close()
+	stream.Send(restful.ServerSentEvent{ID: "42", Event: "status", Data: "running"})
+
+WebSockets
+
+A WebSocket Route is bound using ToWebSocket ; its filters (e.g. for authentication or CORS) are processed before the upgrade.
+The WebSocketConn reads and writes text and binary messages, answers pings and reports close codes using WebSocketCloseError.
+Use a WebSocketUpgrader with ToWebSocketWith to select subprotocols, check the Origin or limit the message size. Request timeouts and content encoding
+do not apply to WebSocket Routes.
+
+	ws.Route(ws.GET("/chat").ToWebSocket(func(conn *restful.WebSocketConn, req *restful.Request) {
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(messageType, data)
+		}
+	}))
+
+Forms and file uploads
+
+FormParameter returns a value of a url-encoded or multipart/form-data body ; FormFile returns an uploaded file.
+File parts larger than the MultipartMemory of the Container are spooled to temporary files, which are removed after the request.
+FormParts reads the parts while the body is received, with a maximum size per part. Use FormParameter on the WebService
+to document form parameters (DataType "file" for uploads).
+
+	ws.Route(ws.POST("/images").Consumes(restful.MIME_MultipartForm).
+		Param(ws.FormParameter("image", "the image").DataType("file").Required(true)).
+		To(postImage))
+
+Access logging
+
+An AccessLog writes a line for each request in the NCSA Common or Combined format or as JSON (including the Route template,
+the Operation and the duration) to any io.Writer. Set its SampleRate to log only a fraction of the requests.
+
+	restful.Filter(restful.NewAccessLog(os.Stdout, restful.CombinedLogFormat).Filter)
+
+Conditional requests
+
+Use SetETag and SetLastModified to describe the current version of a resource and CheckPreconditions to
+evaluate If-Match, If-None-Match, If-Modified-Since and If-Unmodified-Since, responding with 304 or 412.
+
+	if !resp.CheckPreconditions(order.Version, order.Modified) {
+		return // 304 or 412 is written
+	}
+
+With container.EnableETags(true), a strong ETag is computed for entities written by WriteEntity in response to GET and HEAD
+requests, and conditional GET requests are answered with 304 (Not Modified).
+
+Request body size
+
+The size of request bodies can be limited for a Container, WebService or Route (the most specific applies).
+A request with a larger Content-Length gets a 413 response ; for a body of unknown length, ReadEntity returns
+a ServiceError with code 413 when the limit is exceeded while decoding.
+
+	container.MaxBodySize(1 << 20)
+	ws.Route(ws.POST("/images").MaxBodySize(10 << 20).To(upload))
+
+ReadEntity decodes the entity while reading the body. Call req.CacheBody() before the first ReadEntity to read it more than once.
+
+Authentication
+
+An Authentication filter resolves the principal of a request using Authenticators for the Basic, Bearer or API key schemes.
+Routes (or WebServices) declare which schemes apply ; these are also documented by the swagger package.
+Requests without valid credentials get a 401 response with a WWW-Authenticate header.
+
+	auth := restful.NewAuthentication(restful.NewBasicAuthenticator("Orders", checkPassword))
+	ws.Filter(auth.Filter)
+	ws.Route(ws.GET("/orders").Security("basic").To(listOrders))
+	...
+	user := req.Principal()
+
+JSON Web Tokens signed with HS256, RS256 or ES256 are verified by a JWTVerifier using keys of a JWTKeySet,
+e.g. loaded from a JWKS document. The claims are the principal and are available as a Request attribute.
+
+	keys, err := restful.NewJWKSFileKeySet("/etc/idp/jwks.json")
+	verifier := restful.NewJWTVerifier(keys) // requires the exp claim
+	verifier.Issuer, verifier.Audience, verifier.ClockSkew = "https://idp.example.com", "orders", time.Minute
+	ws.Filter(restful.NewJWTFilter("orders", verifier))
+	...
+	claims := req.Attribute(restful.JWTClaimsAttribute).(*restful.JWTClaims)
+
+Routes (or WebServices) can declare the roles or scopes that the principal must have ; the AuthorizationFilter
+responds with 403 otherwise. The principal must implement RoleHolder and/or ScopeHolder.
+
+	ws.Route(ws.DELETE("/orders/{id}").Roles("admin").To(deleteOrder))
+	ws.Route(ws.PUT("/orders/{id}").Scopes("orders:write").To(updateOrder))
+
+Rate limiting
+
+A RateLimiter limits the requests per client, header value, Request attribute or Route using token buckets.
+Requests over the limit get a 429 response with a Retry-After header.
+
+	limiter := restful.NewRateLimiter(10, 20, restful.RemoteAddressKey) // 10 per second, bursts of 20
+	ws.Filter(limiter.Filter)
+
+Metrics
+
+A Metrics value records the number of requests, their duration and the response size for each Route (Method and Path template).
+Install its Filter and add its WebService to serve the data in the Prometheus text format.
+
+	metrics := restful.NewMetrics()
+	restful.Filter(metrics.Filter)
+	restful.Add(metrics.WebService("/metrics"))
+
+HEAD support
+
+A HEAD request for which no HEAD Route exists is dispatched to the matching GET Route, through all filters.
+The body is discarded ; the headers, including Content-Length, are as for GET. Responses to HEAD requests are not compressed.
+
 OPTIONS support
 
 By installing a pre-defined container filter, your Webservice(s) can respond to the OPTIONS Http request.
@@ -95,6 +232,49 @@ By installing the filter of a CrossOriginResourceSharing (CORS), your WebService
 	cors := CrossOriginResourceSharing{ExposeHeaders: []string{"X-My-Header"}, CookiesAllowed: false, Container: DefaultContainer}
 	Filter(cors.Filter)
 
+Request context
+
+Each Request carries a context.Context that is cancelled when the client goes away or when the dispatch is done.
+Filters can replace it, e.g. to add a deadline or a value, for all filters and the RouteFunction that follow.
+
+	req.SetContext(context.WithValue(req.Context(), principalKey, principal))
+	chain.ProcessFilter(req, resp)
+
+Timeouts
+
+The duration of calling the filters and the function of a Route can be bounded for a Container, a WebService or a Route.
+When the timeout expires, the context of the Request is cancelled, a 503 ServiceError is written (if nothing was written yet)
+and any later writes of the function are discarded.
+
+	restful.DefaultContainer.Timeout(30 * time.Second)
+	ws.Route(ws.GET("/report").Timeout(2 * time.Minute).To(buildReport))
+	ws.Route(ws.GET("/events").NoTimeout().To(streamEvents)) // e.g. Server-Sent Events
+
+Parameter validation
+
+Parameters documented on a Route can be checked before its function is called by installing the ValidateParameters filter.
+Values that are missing (if Required), not convertible to their DataType or not allowable are reported using a 400 response.
+
+	ws.Filter(restful.ValidateParameters)
+
+Typed accessors such as QueryParameterInt64, PathParameterBool and HeaderParameterTime return a ParameterError if a value cannot be converted.
+
+To read all parameters at once, populate a struct whose fields are tagged with their source:
+
+	type listParams struct {
+		ID    int64  `path:"id"`
+		Limit int    `query:"limit" default:"10"`
+		Trace string `header:"X-Trace"`
+	}
+	params := listParams{}
+	if err := req.ReadParameters(&params); err != nil {
+		if serviceError, ok := err.(restful.ServiceError); ok {
+			resp.WriteServiceError(serviceError.Code, serviceError) // 400
+			return
+		}
+		resp.WriteError(http.StatusInternalServerError, err) // e.g. an unsupported field type
+	}
+
 Error Handling
 
 Unexpected things happen. If a request cannot be processed because of a failure, your service needs to tell via the response what happened and why.
@@ -127,6 +307,29 @@ The request does not have or has an unknown Content-Type Header set for this ope
 ServiceError
 
 In addition to setting the correct (error) Http status code, you can choose to write a ServiceError message on the response.
+The errors above are written by the Container as a ServiceError entity, using the Accept header to select its representation.
+This can be changed using a ServiceErrorHandleFunction, e.g. for RFC 7807 problem details:
+
+	restful.DefaultContainer.ServiceErrorHandler(restful.WriteProblemDetails)
+
+Entity encodings
+
+Request.ReadEntity and Response.WriteEntity use an EntityReaderWriter per MIME type. JSON and XML are registered by default.
+Register your own to support other formats; Routes that do not specify Produces or Consumes accept all registered MIME types.
+
+	restful.RegisterEntityAccessor("application/x-protobuf", protobufReaderWriter{})
+
+	// or for one Container only
+	container.RegisterEntityAccessor("text/csv", csvReaderWriter{})
+
+Graceful shutdown
+
+A Container keeps track of the requests it is dispatching. Shutdown stops accepting new requests (these get a 503)
+and waits for the requests in progress to complete, or until the context is done.
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	restful.DefaultContainer.Shutdown(ctx)
 
 Serving files
 
@@ -0,0 +1,186 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// EntityReaderWriter can read and write values using an encoding such as JSON or XML.
+// Implementations are registered per MIME type using RegisterEntityAccessor.
+type EntityReaderWriter interface {
+	// Read decodes the content of body into the value pointed to by entityPointer.
+	Read(body io.Reader, entityPointer interface{}) error
+
+	// Write encodes the value and writes the result to w.
+	Write(w io.Writer, value interface{}) error
+}
+
+// entityAccessRegistry maps MIME types to EntityReaderWriter implementations.
+// Lookups that fail are delegated to the parent registry (if any).
+type entityAccessRegistry struct {
+	protection sync.RWMutex
+	accessors  map[string]EntityReaderWriter
+	mimeTypes  []string // in order of registration
+	parent     *entityAccessRegistry
+}
+
+// defaultEntityAccessors is the package default registry ; each Container delegates to it.
+var defaultEntityAccessors = newEntityAccessRegistry(nil)
+
+func init() {
+	RegisterEntityAccessor(MIME_JSON, entityJSONAccess{})
+	RegisterEntityAccessor(MIME_XML, entityXMLAccess{})
+}
+
+func newEntityAccessRegistry(parent *entityAccessRegistry) *entityAccessRegistry {
+	return &entityAccessRegistry{
+		accessors: map[string]EntityReaderWriter{},
+		mimeTypes: []string{},
+		parent:    parent}
+}
+
+// RegisterEntityAccessor adds or replaces the EntityReaderWriter for a MIME type in the package default registry.
+// The registration is visible to all Containers that do not have their own registration for that MIME type.
+func RegisterEntityAccessor(mimeType string, erw EntityReaderWriter) {
+	defaultEntityAccessors.register(mimeType, erw)
+}
+
+func (r *entityAccessRegistry) register(mimeType string, erw EntityReaderWriter) {
+	r.protection.Lock()
+	defer r.protection.Unlock()
+	key := normalizedMimeType(mimeType)
+	if _, exists := r.accessors[key]; !exists {
+		r.mimeTypes = append(r.mimeTypes, key)
+	}
+	r.accessors[key] = erw
+}
+
+// accessorAt returns the EntityReaderWriter for a MIME type ; parameters such as charset are ignored.
+func (r *entityAccessRegistry) accessorAt(mimeType string) (EntityReaderWriter, bool) {
+	key := normalizedMimeType(mimeType)
+	for each := r; each != nil; each = each.parent {
+		each.protection.RLock()
+		erw, ok := each.accessors[key]
+		each.protection.RUnlock()
+		if ok {
+			return erw, true
+		}
+	}
+	return nil, false
+}
+
+// registeredMimeTypes returns all MIME types that can be read or written, own registrations first.
+func (r *entityAccessRegistry) registeredMimeTypes() []string {
+	types := []string{}
+	seen := map[string]bool{}
+	for each := r; each != nil; each = each.parent {
+		each.protection.RLock()
+		for _, mime := range each.mimeTypes {
+			if !seen[mime] {
+				seen[mime] = true
+				types = append(types, mime)
+			}
+		}
+		each.protection.RUnlock()
+	}
+	return types
+}
+
+// orDefault returns the receiver or the package default registry if the receiver is nil.
+func (r *entityAccessRegistry) orDefault() *entityAccessRegistry {
+	if r == nil {
+		return defaultEntityAccessors
+	}
+	return r
+}
+
+// normalizedMimeType returns the lowercase MIME type without any parameters (e.g. charset).
+func normalizedMimeType(mimeType string) string {
+	if i := strings.Index(mimeType, ";"); i != -1 {
+		mimeType = mimeType[:i]
+	}
+	return strings.ToLower(strings.Trim(mimeType, " "))
+}
+
+// entityJSONAccess is the EntityReaderWriter for MIME_JSON
+type entityJSONAccess struct{}
+
+// errTrailingData is returned when reading an entity from a body that has more content after the entity.
+var errTrailingData = errors.New("[restful] unexpected data after entity")
+
+// Read is part of EntityReaderWriter ; the body must contain exactly one JSON value.
+func (e entityJSONAccess) Read(body io.Reader, entityPointer interface{}) error {
+	decoder := json.NewDecoder(body)
+	if err := decoder.Decode(entityPointer); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		if err != nil {
+			return err
+		}
+		return errTrailingData
+	}
+	return nil
+}
+
+// Write is part of EntityReaderWriter
+func (e entityJSONAccess) Write(w io.Writer, value interface{}) error {
+	output, err := json.MarshalIndent(value, " ", " ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(output)
+	return err
+}
+
+// entityXMLAccess is the EntityReaderWriter for MIME_XML
+type entityXMLAccess struct{}
+
+// Read is part of EntityReaderWriter ; the body must contain exactly one XML element, optionally followed by
+// whitespace, comments and processing instructions.
+func (e entityXMLAccess) Read(body io.Reader, entityPointer interface{}) error {
+	decoder := xml.NewDecoder(body)
+	if err := decoder.Decode(entityPointer); err != nil {
+		return err
+	}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch each := token.(type) {
+		case xml.Comment, xml.ProcInst:
+		case xml.CharData:
+			if len(bytes.TrimSpace(each)) > 0 {
+				return errTrailingData
+			}
+		default:
+			return errTrailingData
+		}
+	}
+}
+
+// Write is part of EntityReaderWriter
+func (e entityXMLAccess) Write(w io.Writer, value interface{}) error {
+	output, err := xml.MarshalIndent(value, " ", " ")
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	_, err = w.Write(output)
+	return err
+}
@@ -0,0 +1,121 @@
+package restful
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// upperTextAccess is a test EntityReaderWriter that reads and writes *Sample values as plain uppercase text.
+type upperTextAccess struct{}
+
+func (u upperTextAccess) Read(body io.Reader, entityPointer interface{}) error {
+	content, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	entityPointer.(*Sample).Value = strings.ToUpper(string(content))
+	return nil
+}
+
+func (u upperTextAccess) Write(w io.Writer, value interface{}) error {
+	_, err := io.WriteString(w, strings.ToUpper(value.(Sample).Value))
+	return err
+}
+
+func TestReadEntityTrailingData(t *testing.T) {
+	for _, each := range []struct {
+		contentType, body string
+		valid             bool
+	}{
+		{MIME_JSON, `{"Value":"42"} `, true},
+		{MIME_JSON, `{"Value":"42"}{"Value":"43"}`, false},
+		{MIME_JSON, `{"Value":"42"}]`, false},
+		{MIME_JSON, `{"Value":"42"} x`, false},
+		{MIME_XML, "<Sample><Value>42</Value></Sample>\n<!-- end -->\n", true},
+		{MIME_XML, "<Sample><Value>42</Value></Sample><Sample/>", false},
+		{MIME_XML, "<Sample><Value>42</Value></Sample> trailing", false},
+	} {
+		httpRequest, _ := http.NewRequest("PUT", "/samples", strings.NewReader(each.body))
+		httpRequest.Header.Set(HEADER_ContentType, each.contentType)
+		sam := new(Sample)
+		err := newRequest(httpRequest).ReadEntity(sam)
+		if each.valid != (err == nil) {
+			t.Errorf("%s %q: expected valid=%v, got %v", each.contentType, each.body, each.valid, err)
+		}
+		if each.valid && sam.Value != "42" {
+			t.Errorf("%q: unexpected value:%q", each.body, sam.Value)
+		}
+	}
+}
+
+func TestEntityAccessRegistry_Lookup(t *testing.T) {
+	parent := newEntityAccessRegistry(nil)
+	parent.register(MIME_JSON, entityJSONAccess{})
+	child := newEntityAccessRegistry(parent)
+	child.register("Text/Upper", upperTextAccess{})
+
+	if _, ok := child.accessorAt("text/upper; charset=UTF-8"); !ok {
+		t.Error("expected accessor for text/upper with parameters")
+	}
+	if _, ok := child.accessorAt(MIME_JSON); !ok {
+		t.Error("expected accessor for json from parent")
+	}
+	if _, ok := parent.accessorAt("text/upper"); ok {
+		t.Error("parent should not see child registration")
+	}
+	types := child.registeredMimeTypes()
+	if len(types) != 2 || types[0] != "text/upper" || types[1] != MIME_JSON {
+		t.Errorf("unexpected mime types:%v", types)
+	}
+}
+
+// go test -v -test.run TestReadEntityRegisteredType ...restful
+func TestReadEntityRegisteredType(t *testing.T) {
+	registry := newEntityAccessRegistry(defaultEntityAccessors)
+	registry.register("text/upper", upperTextAccess{})
+	httpRequest, _ := http.NewRequest("POST", "/test", strings.NewReader("hello"))
+	httpRequest.Header.Set("Content-Type", "text/upper")
+	request := newRequest(httpRequest)
+	request.accessors = registry
+	sam := new(Sample)
+	if err := request.ReadEntity(sam); err != nil {
+		t.Fatal(err)
+	}
+	if sam.Value != "HELLO" {
+		t.Fatalf("read failed:%v", sam.Value)
+	}
+}
+
+// go test -v -test.run TestContainerEntityAccessor ...restful
+func TestContainerEntityAccessor(t *testing.T) {
+	container := NewContainer()
+	container.RegisterEntityAccessor("text/upper", upperTextAccess{})
+	ws := new(WebService).Path("/samples")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		resp.WriteEntity(Sample{"hello"})
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/samples", nil)
+	httpRequest.Header.Set("Accept", "text/upper")
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != http.StatusOK {
+		t.Fatalf("unexpected status:%d", httpWriter.Code)
+	}
+	if ct := httpWriter.Header().Get(HEADER_ContentType); ct != "text/upper" {
+		t.Errorf("unexpected content-type:%s", ct)
+	}
+	if body := httpWriter.Body.String(); body != "HELLO" {
+		t.Errorf("unexpected body:%s", body)
+	}
+
+	// not registered on the default container
+	if _, ok := DefaultContainer.entityAccessors.accessorAt("text/upper"); ok {
+		t.Error("registration should not leak into other containers")
+	}
+}
@@ -1,35 +1,35 @@
 package main
 
 import (
+	"errors"
 	"github.com/squishyent/go-restful"
 	"io"
 	"net/http"
 )
 
-// This example shows how to create a (Route) Filter that performs Basic Authentication on the Http request.
+// This example shows how to install a Filter that performs Basic Authentication on the Http request.
+// The authenticated principal is available using Request.Principal.
+// Use restful.NewBearerAuthenticator or restful.NewAPIKeyAuthenticator for other schemes.
 //
 // GET http://localhost:8080/secret
 // and use admin,admin for the credentials
 
 func main() {
+	auth := restful.NewAuthentication(restful.NewBasicAuthenticator("Protected Area", checkPassword))
 	ws := new(restful.WebService)
-	ws.Route(ws.GET("/secret").Filter(basicAuthenticate).To(secret))
+	ws.Route(ws.GET("/secret").Filter(auth.Filter).To(secret))
 	restful.Add(ws)
 	http.ListenAndServe(":8080", nil)
 }
 
-func basicAuthenticate(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
-	encoded := req.Request.Header.Get("Authorization")
-	// usr/pwd = admin/admin
-	// real code does some decoding
-	if len(encoded) == 0 || "Basic YWRtaW46YWRtaW4=" != encoded {
-		resp.AddHeader("WWW-Authenticate", "Basic realm=Protected Area")
-		resp.WriteErrorString(401, "401: Not Authorized")
-		return
+func checkPassword(username, password string) (interface{}, error) {
+	// real code looks up the user
+	if username != "admin" || password != "admin" {
+		return nil, errors.New("invalid username or password")
 	}
-	chain.ProcessFilter(req, resp)
+	return username, nil
 }
 
 func secret(req *restful.Request, resp *restful.Response) {
-	io.WriteString(resp, "42")
+	io.WriteString(resp, "42 for "+req.Principal().(string))
 }
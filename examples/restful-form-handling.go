@@ -10,6 +10,7 @@ import (
 
 // This example shows how to handle a POST of a HTML form that uses the standard x-www-form-urlencoded content-type.
 // It uses the gorilla web tool kit schema package to decode the form data into a struct.
+// See restful-multipart-upload.go for forms with file uploads.
 //
 // GET http://localhost:8080/profiles
 //
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/squishyent/go-restful"
+)
+
+// This example shows how to handle a POST of a HTML form that uses the multipart/form-data content-type.
+// Small uploads are read using FormFile ; large uploads are streamed to disk part by part using FormParts.
+//
+// GET http://localhost:8080/images
+//
+
+func main() {
+	restful.DefaultContainer.MaxBodySize(100 << 20)
+	restful.DefaultContainer.MultipartMemory(1 << 20) // larger files are spooled to temporary files
+
+	ws := new(restful.WebService).Path("/images")
+	ws.Route(ws.GET("").To(imageForm))
+	ws.Route(ws.POST("").Consumes(restful.MIME_MultipartForm).
+		Param(ws.FormParameter("title", "title of the image")).
+		Param(ws.FormParameter("image", "the image").DataType("file").Required(true)).
+		Filter(restful.ValidateParameters).
+		To(postImage))
+	ws.Route(ws.POST("/large").Consumes(restful.MIME_MultipartForm).
+		Param(ws.FormParameter("image", "the image").DataType("file")).
+		To(postLargeImage))
+	restful.Add(ws)
+	http.ListenAndServe(":8080", nil)
+}
+
+func postImage(req *restful.Request, resp *restful.Response) {
+	title, _ := req.FormParameter("title")
+	file, header, err := req.FormFile("image")
+	if err != nil {
+		resp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+	size, _ := io.Copy(io.Discard, file)
+	io.WriteString(resp, title+": "+header.Filename+" has "+strconv.FormatInt(size, 10)+" bytes")
+}
+
+func postLargeImage(req *restful.Request, resp *restful.Response) {
+	parts, err := req.FormParts(50 << 20)
+	if err != nil {
+		resp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			resp.WriteErrorString(http.StatusBadRequest, err.Error())
+			return
+		}
+		if part.FormName() != "image" {
+			continue
+		}
+		out, err := os.Create(filepath.Join(os.TempDir(), filepath.Base(part.FileName())))
+		if err != nil {
+			resp.WriteErrorString(http.StatusInternalServerError, err.Error())
+			return
+		}
+		_, err = io.Copy(out, part)
+		out.Close()
+		if serviceError, ok := err.(restful.ServiceError); ok {
+			resp.WriteServiceError(serviceError.Code, serviceError)
+			return
+		}
+	}
+	io.WriteString(resp, "stored")
+}
+
+func imageForm(req *restful.Request, resp *restful.Response) {
+	io.WriteString(resp.ResponseWriter,
+		`<html>
+		<body>
+		<h1>Upload Image</h1>
+		<form method="post" enctype="multipart/form-data">
+			<label>Title:</label>
+			<input type="text" name="title"/>
+			<input type="file" name="image"/>
+			<input type="Submit" />
+		</form>
+		</body>
+		</html>`)
+}
@@ -0,0 +1,46 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// headResponseWriter is used for HEAD requests, which may be dispatched to a GET Route.
+// It discards the body but counts its bytes such that the Content-Length header is the same as for GET.
+// The status is written by finish.
+type headResponseWriter struct {
+	http.ResponseWriter
+	status int // zero means 200
+	length int
+}
+
+// WriteHeader is part of http.ResponseWriter ; the status is written by finish.
+func (h *headResponseWriter) WriteHeader(status int) {
+	if h.status == 0 {
+		h.status = status
+	}
+}
+
+// Write is part of http.ResponseWriter ; the data is discarded.
+func (h *headResponseWriter) Write(data []byte) (int, error) {
+	h.length += len(data)
+	return len(data), nil
+}
+
+// Flush is part of http.Flusher ; it does nothing because the status and Content-Length are written by finish.
+func (h *headResponseWriter) Flush() {}
+
+// finish sets the Content-Length header (unless set) and writes the status.
+func (h *headResponseWriter) finish() {
+	if h.length > 0 && h.Header().Get("Content-Length") == "" && h.Header().Get("Transfer-Encoding") == "" {
+		h.Header().Set("Content-Length", strconv.Itoa(h.length))
+	}
+	if h.status == 0 {
+		h.status = http.StatusOK
+	}
+	h.ResponseWriter.WriteHeader(h.status)
+}
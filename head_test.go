@@ -0,0 +1,94 @@
+package restful
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHEADFallsBackToGET(t *testing.T) {
+	ws := new(WebService).Path("/orders")
+	ws.Route(ws.GET("/{id}").To(dummy))
+	ws.Route(ws.HEAD("/{id}/lines").To(dummy))
+	ws.Route(ws.GET("/{id}/lines").To(dummy))
+	payments := new(WebService).Path("/payments")
+	payments.Route(payments.POST("").To(dummy))
+	for _, router := range []RouteSelector{RouterJSR311{}, CurlyRouter{}, new(TrieRouter)} {
+		for url, method := range map[string]string{"/orders/1": "GET", "/orders/1/lines": "HEAD"} {
+			httpRequest, _ := http.NewRequest("HEAD", "http://here.com"+url, nil)
+			_, route, err := router.SelectRoute([]*WebService{ws}, httpRequest)
+			if err != nil || route.Method != method {
+				t.Errorf("%T %s: expected %s Route, got %v %v", router, url, method, route, err)
+			}
+		}
+		httpRequest, _ := http.NewRequest("HEAD", "http://here.com/payments", nil)
+		if _, _, err := router.SelectRoute([]*WebService{payments}, httpRequest); err == nil || err.(ServiceError).Code != 405 {
+			t.Errorf("%T: expected 405 without GET Route, got %v", router, err)
+		}
+	}
+}
+
+// go test -v -test.run TestHEADResponse ...restful
+func TestHEADResponse(t *testing.T) {
+	calls := 0
+	container := NewContainer()
+	container.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		calls++
+		chain.ProcessFilter(req, resp)
+	})
+	ws := new(WebService).Path("/orders")
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		resp.Header().Set("X-Order", req.PathParameter("id"))
+		resp.WriteHeader(http.StatusAccepted)
+		io.WriteString(resp, "order 42")
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("HEAD", "http://here.com/orders/42", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if calls != 1 {
+		t.Errorf("expected the filter to be called, got %d", calls)
+	}
+	if httpWriter.Code != http.StatusAccepted || httpWriter.Body.Len() != 0 {
+		t.Errorf("unexpected response:%d %q", httpWriter.Code, httpWriter.Body.String())
+	}
+	if httpWriter.Header().Get("X-Order") != "42" || httpWriter.Header().Get("Content-Length") != "8" {
+		t.Errorf("unexpected headers:%v", httpWriter.Header())
+	}
+}
+
+// go test -v -test.run TestHEADResponseCompressed ...restful
+func TestHEADResponseCompressed(t *testing.T) {
+	container := NewContainer()
+	container.EnableContentEncoding(true)
+	ws := new(WebService).Path("/orders")
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		io.WriteString(resp, strings.Repeat("order "+req.PathParameter("id")+" ", 100))
+	}))
+	container.Add(ws)
+	server := httptest.NewServer(container)
+	defer server.Close()
+
+	headers := map[string]http.Header{}
+	for _, method := range []string{"GET", "HEAD"} {
+		httpRequest, _ := http.NewRequest(method, server.URL+"/orders/42", nil)
+		httpRequest.Header.Set(HEADER_AcceptEncoding, ENCODING_GZIP)
+		httpResponse, err := http.DefaultClient.Do(httpRequest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		httpResponse.Body.Close()
+		headers[method] = httpResponse.Header
+	}
+	for _, each := range []string{HEADER_ContentEncoding, "Content-Length"} {
+		if get, head := headers["GET"].Get(each), headers["HEAD"].Get(each); get == "" || get != head {
+			t.Errorf("%s: GET %q and HEAD %q should be equal", each, get, head)
+		}
+	}
+	if headers["GET"].Get("Content-Length") == "600" {
+		t.Error("expected the compressed length")
+	}
+}
@@ -26,8 +26,7 @@ func (r RouterJSR311) SelectRoute(
 	// Identify the root resource class (WebService)
 	dispatcher, finalMatch, err := r.detectDispatcher(httpRequest.URL.Path, webServices)
 	if err != nil {
-		// httpWriter.WriteHeader(http.StatusNotFound)
-		return nil, nil, NewError(http.StatusNotFound, "")
+		return nil, nil, NewError(http.StatusNotFound, "404: Page Not Found")
 	}
 	// Obtain the set of candidate methods (Routes)
 	routes := r.selectRoutes(dispatcher, finalMatch)
@@ -40,11 +39,10 @@ func (r RouterJSR311) SelectRoute(
 // http://jsr311.java.net/nonav/releases/1.1/spec/spec3.html#x3-360003.7.2
 func (r RouterJSR311) detectRoute(routes []Route, httpRequest *http.Request) (*Route, error) {
 	// http method
-	methodOk := []Route{}
-	for _, each := range routes {
-		if httpRequest.Method == each.Method {
-			methodOk = append(methodOk, each)
-		}
+	methodOk := routesWithMethod(routes, httpRequest.Method)
+	if len(methodOk) == 0 && httpRequest.Method == "HEAD" {
+		// fall back to GET ; the Container discards the body
+		methodOk = routesWithMethod(routes, "GET")
 	}
 	if len(methodOk) == 0 {
 		return nil, NewError(http.StatusMethodNotAllowed, "405: Method Not Allowed")
@@ -53,7 +51,7 @@ func (r RouterJSR311) detectRoute(routes []Route, httpRequest *http.Request) (*R
 	// content-type
 	contentType := httpRequest.Header.Get(HEADER_ContentType)
 	if httpRequest.ContentLength > 0 {
-		inputMediaOk = []Route{}
+		inputMediaOk = []*Route{}
 		for _, each := range methodOk {
 			if each.matchesContentType(contentType) {
 				inputMediaOk = append(inputMediaOk, each)
@@ -64,7 +62,7 @@ func (r RouterJSR311) detectRoute(routes []Route, httpRequest *http.Request) (*R
 		}
 	}
 	// accept
-	outputMediaOk := []Route{}
+	outputMediaOk := []*Route{}
 	accept := httpRequest.Header.Get(HEADER_Accept)
 	if accept == "" {
 		accept = "*/*"
@@ -80,11 +78,68 @@ func (r RouterJSR311) detectRoute(routes []Route, httpRequest *http.Request) (*R
 	return r.bestMatchByMedia(outputMediaOk, contentType, accept), nil
 }
 
+// routesWithMethod returns pointers to the routes for the http method.
+func routesWithMethod(routes []Route, method string) []*Route {
+	selected := []*Route{}
+	for i := range routes {
+		if method == routes[i].Method {
+			selected = append(selected, &routes[i])
+		}
+	}
+	return selected
+}
+
 // http://jsr311.java.net/nonav/releases/1.1/spec/spec3.html#x3-360003.7.2
 // n/m > n/* > */*
-func (r RouterJSR311) bestMatchByMedia(routes []Route, contentType string, accept string) *Route {
-	// TODO
-	return &routes[0]
+// The primary key is the Consumes that matches the Content-Type, the secondary key is the Produces that matches the Accept.
+// If Routes are equal then the first is selected.
+func (r RouterJSR311) bestMatchByMedia(routes []*Route, contentType string, accept string) *Route {
+	ranges := parseAccept(accept)
+	best := routes[0]
+	bestScore := newMediaScore(best, contentType, ranges)
+	for _, each := range routes[1:] {
+		if score := newMediaScore(each, contentType, ranges); score.betterThan(bestScore) {
+			best, bestScore = each, score
+		}
+	}
+	return best
+}
+
+// mediaScore holds the keys to compare Routes that match the same request on their Consumes and Produces.
+type mediaScore struct {
+	consumes           int     // specificity of the Consumes matching the Content-Type, -1 if not applicable
+	quality            float64 // highest quality of a Produces in the Accept header
+	acceptSpecificity  int     // specificity of the Accept media range for that quality
+	produceSpecificity int     // specificity of the Produces for that quality
+}
+
+func newMediaScore(route *Route, contentType string, ranges []mediaRange) mediaScore {
+	score := mediaScore{consumes: -1, acceptSpecificity: -1, produceSpecificity: -1}
+	if contentType != "" {
+		score.consumes = route.consumesSpecificity(contentType)
+	}
+	for _, each := range route.producibleTypes() {
+		quality, specificity := offerQuality(ranges, each)
+		produced := parseMediaRange(each, 0).specificity()
+		candidate := mediaScore{score.consumes, quality, specificity, produced}
+		if candidate.betterThan(score) {
+			score = candidate
+		}
+	}
+	return score
+}
+
+func (m mediaScore) betterThan(other mediaScore) bool {
+	if m.consumes != other.consumes {
+		return m.consumes > other.consumes
+	}
+	if m.quality != other.quality {
+		return m.quality > other.quality
+	}
+	if m.acceptSpecificity != other.acceptSpecificity {
+		return m.acceptSpecificity > other.acceptSpecificity
+	}
+	return m.produceSpecificity > other.produceSpecificity
 }
 
 // http://jsr311.java.net/nonav/releases/1.1/spec/spec3.html#x3-360003.7.2  (step 2)
@@ -100,7 +155,7 @@ func (r RouterJSR311) selectRoutes(dispatcher *WebService, pathRemainder string)
 			lastMatch := matches[len(matches)-1]
 			if lastMatch == "" || lastMatch == "/" { // do not include if value is neither empty nor ‘/’.
 				filtered.candidates = append(filtered.candidates,
-					routeCandidate{each, len(matches) - 1, pathExpr.LiteralCount, pathExpr.VarCount})
+					routeCandidate{each, len(matches) - 1, pathExpr.LiteralCount, pathExpr.NonDefaultCount, pathExpr.hasCatchAll()})
 			}
 		}
 	}
@@ -128,7 +183,7 @@ func (r RouterJSR311) detectDispatcher(requestPath string, dispatchers []*WebSer
 		matches := pathExpr.Matcher.FindStringSubmatch(requestPath)
 		if matches != nil {
 			filtered.candidates = append(filtered.candidates,
-				dispatcherCandidate{each, matches[len(matches)-1], len(matches), pathExpr.LiteralCount, pathExpr.VarCount})
+				dispatcherCandidate{each, matches[len(matches)-1], len(matches), pathExpr.LiteralCount, pathExpr.NonDefaultCount})
 		}
 	}
 	if len(filtered.candidates) == 0 {
@@ -142,9 +197,10 @@ func (r RouterJSR311) detectDispatcher(requestPath string, dispatchers []*WebSer
 
 type routeCandidate struct {
 	route           Route
-	matchesCount    int // the number of capturing groups
-	literalCount    int // the number of literal characters (means those not resulting from template variable substitution)
-	nonDefaultCount int // the number of capturing groups with non-default regular expressions (i.e. not ‘([^  /]+?)’)
+	matchesCount    int  // the number of capturing groups
+	literalCount    int  // the number of literal characters (means those not resulting from template variable substitution)
+	nonDefaultCount int  // the number of capturing groups with non-default regular expressions (i.e. not ‘([^  /]+?)’)
+	catchAll        bool // whether the last capturing group matches the remainder of the path
 }
 
 func (r routeCandidate) expressionToMatch() string {
@@ -175,6 +231,10 @@ func (rcs *sortableRouteCandidates) Less(i, j int) bool {
 	if ci.literalCount > cj.literalCount {
 		return false
 	}
+	// a catch-all is the least specific
+	if ci.catchAll != cj.catchAll {
+		return ci.catchAll
+	}
 	// secundary key
 	if ci.matchesCount < cj.matchesCount {
 		return true
@@ -2,6 +2,7 @@ package restful
 
 import (
 	"io"
+	"net/http"
 	"sort"
 	"testing"
 )
@@ -162,6 +163,8 @@ var tempregexs = []struct {
 	{"/a/{b}/c/", "^/a/([^/]+?)/c(/.*)?$", 2, 1},
 	{"/{a}/{b}/{c-d-e}/", "^/([^/]+?)/([^/]+?)/([^/]+?)(/.*)?$", 0, 3},
 	{"/{p}/abcde", "^/([^/]+?)/abcde(/.*)?$", 5, 1},
+	{"/a/{id:[0-9]+}", "^/a/([0-9]+)(/.*)?$", 1, 1},
+	{"/files/{path:*}", "^/files(?:/(.*))?(/.*)?$", 5, 1},
 }
 
 func TestTemplateToRegularExpression(t *testing.T) {
@@ -206,4 +209,64 @@ func TestSortableRouteCandidates(t *testing.T) {
 	}
 }
 
+// go test -v -test.run TestSelectRoutesNonDefault ...restful
+func TestSelectRoutesNonDefault(t *testing.T) {
+	ws1 := new(WebService).Path("/")
+	ws1.Route(ws1.GET("/{name}").To(dummy))
+	ws1.Route(ws1.GET("/{id:[0-9]+}").To(dummy))
+	ws1.Route(ws1.GET("/{rest:*}").To(dummy))
+	routes := RouterJSR311{}.selectRoutes(ws1, "/12")
+	if len(routes) != 3 {
+		t.Fatal("expected 3 routes")
+	}
+	if routes[0].Path != "/{id:[0-9]+}" || routes[2].Path != "/{rest:*}" {
+		t.Error("unexpected order", routes)
+	}
+	routes = RouterJSR311{}.selectRoutes(ws1, "/ab")
+	if len(routes) != 2 || routes[0].Path != "/{name}" {
+		t.Errorf("expected /{name} first, got:%v", routes)
+	}
+}
+
+// go test -v -test.run TestSelectRouteCatchAll ...restful
+func TestSelectRouteCatchAll(t *testing.T) {
+	ws1 := new(WebService).Path("/files")
+	ws1.Route(ws1.GET("/{path:*}").To(dummy))
+	for _, each := range []string{"/files", "/files/a", "/files/a/b/c.txt"} {
+		req, _ := http.NewRequest("GET", "http://here.com"+each, nil)
+		_, route, err := RouterJSR311{}.SelectRoute([]*WebService{ws1}, req)
+		if err != nil {
+			t.Errorf("%s: unexpected error:%v", each, err)
+			continue
+		}
+		if route.Path != "/files/{path:*}" {
+			t.Errorf("%s: unexpected route:%v", each, route.Path)
+		}
+	}
+}
+
+// go test -v -test.run TestBestMatchByMedia ...restful
+func TestBestMatchByMedia(t *testing.T) {
+	ws1 := new(WebService).Path("/")
+	ws1.Route(ws1.POST("/media").Consumes("*/*").Produces(MIME_XML).To(dummy))
+	ws1.Route(ws1.POST("/media").Consumes(MIME_JSON).Produces(MIME_XML).To(dummy))
+	ws1.Route(ws1.POST("/media").Consumes(MIME_JSON).Produces(MIME_JSON).To(dummy))
+	routes := ws1.Routes()
+	candidates := []*Route{&routes[0], &routes[1], &routes[2]}
+	// consumes is the primary key
+	best := RouterJSR311{}.bestMatchByMedia(candidates, MIME_JSON, "application/xml;q=0.5, application/json")
+	if best != candidates[2] {
+		t.Errorf("expected json->json, got:%v %v", best.Consumes, best.Produces)
+	}
+	best = RouterJSR311{}.bestMatchByMedia(candidates, "text/plain", "*/*")
+	if best != candidates[0] {
+		t.Errorf("expected */*->xml, got:%v %v", best.Consumes, best.Produces)
+	}
+	// produces is the secondary key
+	best = RouterJSR311{}.bestMatchByMedia(candidates, MIME_JSON, "application/json;q=0.5, application/xml")
+	if best != candidates[1] {
+		t.Errorf("expected json->xml, got:%v %v", best.Consumes, best.Produces)
+	}
+}
+
 func dummy(req *Request, resp *Response) { io.WriteString(resp.ResponseWriter, "dummy") }
@@ -0,0 +1,435 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JWTClaimsAttribute is the name of the Request attribute that holds the *JWTClaims of a verified token.
+const JWTClaimsAttribute = "restful.jwt.claims"
+
+// JWTClaims holds the registered claims of a verified JSON Web Token (RFC 7519) and all claims as decoded JSON.
+// It implements RoleHolder (claim roles) and ScopeHolder (claim scope, space separated, or scp) such that
+// it can be used as the principal for the AuthorizationFilter.
+type JWTClaims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time // zero if absent
+	NotBefore time.Time // zero if absent
+	IssuedAt  time.Time // zero if absent
+	ID        string
+	Roles     []string
+	Scopes    []string
+	Raw       map[string]interface{}
+}
+
+// HasRole is part of RoleHolder
+func (c *JWTClaims) HasRole(role string) bool {
+	return containsString(c.Roles, role)
+}
+
+// HasScope is part of ScopeHolder
+func (c *JWTClaims) HasScope(scope string) bool {
+	return containsString(c.Scopes, scope)
+}
+
+// JWTKeySet holds the keys to verify token signatures by key id (kid). Keys are []byte (HS256),
+// *rsa.PublicKey (RS256) or *ecdsa.PublicKey (ES256, P-256). A JWTKeySet is safe for concurrent use.
+type JWTKeySet struct {
+	protection sync.RWMutex
+	keys       map[string]interface{}
+	jwksPath   string // if not empty then Reload reads this JWKS document
+}
+
+// NewJWTKeySet returns an empty JWTKeySet ; use AddKey to add the static keys.
+func NewJWTKeySet() *JWTKeySet {
+	return &JWTKeySet{keys: map[string]interface{}{}}
+}
+
+// NewJWKSFileKeySet returns a JWTKeySet with the keys of the JWKS document (RFC 7517) at the path.
+// Call Reload to read the document again, e.g. after the identity provider rotated its keys.
+func NewJWKSFileKeySet(path string) (*JWTKeySet, error) {
+	set := &JWTKeySet{keys: map[string]interface{}{}, jwksPath: path}
+	return set, set.Reload()
+}
+
+// AddKey adds or replaces the key with the id ; use an empty id for tokens without kid.
+func (s *JWTKeySet) AddKey(kid string, key interface{}) {
+	s.protection.Lock()
+	defer s.protection.Unlock()
+	s.keys[kid] = key
+}
+
+// Reload replaces all keys by those of the JWKS document. The keys are unchanged if it cannot be read or parsed.
+// Reload does nothing for a JWTKeySet that was not created by NewJWKSFileKeySet.
+func (s *JWTKeySet) Reload() error {
+	if s.jwksPath == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(s.jwksPath)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+	s.protection.Lock()
+	defer s.protection.Unlock()
+	s.keys = keys
+	return nil
+}
+
+// key returns the key with the id. If the id is empty and there is no key without id then the only key is used.
+func (s *JWTKeySet) key(kid string) (interface{}, bool) {
+	s.protection.RLock()
+	defer s.protection.RUnlock()
+	if key, ok := s.keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(s.keys) == 1 {
+		for _, each := range s.keys {
+			return each, true
+		}
+	}
+	return nil, false
+}
+
+// JWTVerifier verifies the signature and the claims of JSON Web Tokens.
+//
+//	keys, err := restful.NewJWKSFileKeySet("/etc/idp/jwks.json")
+//	verifier := restful.NewJWTVerifier(keys)
+//	verifier.Issuer, verifier.Audience, verifier.ClockSkew = "https://idp.example.com", "orders", time.Minute
+//	ws.Filter(restful.NewJWTFilter("orders", verifier))
+type JWTVerifier struct {
+	Keys       *JWTKeySet
+	Issuer     string        // if not empty then the iss claim must be equal
+	Audience   string        // if not empty then the aud claim must contain it
+	ClockSkew  time.Duration // tolerance for the exp and nbf claims
+	Algorithms []string      // accepted algorithms ; default is HS256, RS256 and ES256
+
+	RequireExpiration bool // if true then tokens without exp claim are rejected ; set by NewJWTVerifier
+	now               func() time.Time
+}
+
+var defaultJWTAlgorithms = []string{"HS256", "RS256", "ES256"}
+
+// NewJWTVerifier returns a JWTVerifier for the keys that requires tokens to have an exp claim.
+func NewJWTVerifier(keys *JWTKeySet) *JWTVerifier {
+	return &JWTVerifier{Keys: keys, RequireExpiration: true}
+}
+
+// Verify returns the claims of the token if its signature and claims are valid.
+func (v *JWTVerifier) Verify(token string) (*JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("[restful] malformed token")
+	}
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	algorithms := v.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = defaultJWTAlgorithms
+	}
+	if !containsString(algorithms, header.Algorithm) {
+		return nil, errors.New("[restful] unaccepted token algorithm:" + header.Algorithm)
+	}
+	key, ok := v.Keys.key(header.KeyID)
+	if !ok {
+		return nil, errors.New("[restful] unknown token key:" + header.KeyID)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("[restful] malformed token signature")
+	}
+	if err := verifyJWTSignature(header.Algorithm, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+	raw := map[string]interface{}{}
+	if err := decodeJWTPart(parts[1], &raw); err != nil {
+		return nil, err
+	}
+	claims, err := newJWTClaims(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validate checks the time, issuer and audience claims.
+func (v *JWTVerifier) validate(claims *JWTClaims) error {
+	now := time.Now()
+	if v.now != nil {
+		now = v.now()
+	}
+	if v.RequireExpiration && claims.ExpiresAt.IsZero() {
+		return errors.New("[restful] token has no expiration")
+	}
+	if !claims.ExpiresAt.IsZero() && now.After(claims.ExpiresAt.Add(v.ClockSkew)) {
+		return errors.New("[restful] token is expired")
+	}
+	if !claims.NotBefore.IsZero() && now.Add(v.ClockSkew).Before(claims.NotBefore) {
+		return errors.New("[restful] token is not valid yet")
+	}
+	if v.Issuer != "" && claims.Issuer != v.Issuer {
+		return errors.New("[restful] unexpected token issuer:" + claims.Issuer)
+	}
+	if v.Audience != "" && !containsString(claims.Audience, v.Audience) {
+		return errors.New("[restful] token is not for audience:" + v.Audience)
+	}
+	return nil
+}
+
+// JWTAuthenticator is an Authenticator for the Bearer scheme with JSON Web Tokens.
+// The principal is the *JWTClaims which is also stored in the Request attribute JWTClaimsAttribute.
+type JWTAuthenticator struct {
+	Name     string // scheme name, default is bearer
+	Realm    string
+	Verifier *JWTVerifier
+}
+
+// NewJWTAuthenticator returns a JWTAuthenticator named bearer.
+func NewJWTAuthenticator(realm string, verifier *JWTVerifier) *JWTAuthenticator {
+	return &JWTAuthenticator{Name: "bearer", Realm: realm, Verifier: verifier}
+}
+
+// NewJWTFilter returns a FilterFunction that authenticates requests using a JWTAuthenticator.
+func NewJWTFilter(realm string, verifier *JWTVerifier) FilterFunction {
+	return NewAuthentication(NewJWTAuthenticator(realm, verifier)).Filter
+}
+
+// SchemeName is part of Authenticator
+func (j *JWTAuthenticator) SchemeName() string {
+	return j.Name
+}
+
+// Authenticate is part of Authenticator
+func (j *JWTAuthenticator) Authenticate(req *Request) (interface{}, error) {
+	token, ok := bearerToken(req.Request)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	claims, err := j.Verifier.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	req.SetAttribute(JWTClaimsAttribute, claims)
+	return claims, nil
+}
+
+// Challenge is part of Authenticator
+func (j *JWTAuthenticator) Challenge() string {
+	return `Bearer realm="` + j.Realm + `"`
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return errors.New("[restful] malformed token")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.New("[restful] malformed token")
+	}
+	return nil
+}
+
+// verifyJWTSignature checks the signature of the signed content using the key ; the key type must match the algorithm.
+func verifyJWTSignature(algorithm string, key interface{}, signed string, signature []byte) error {
+	invalid := errors.New("[restful] invalid token signature")
+	digest := sha256.Sum256([]byte(signed))
+	switch algorithm {
+	case "HS256":
+		secret, ok := key.([]byte)
+		if !ok {
+			return invalid
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return invalid
+		}
+		return nil
+	case "RS256":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature) != nil {
+			return invalid
+		}
+		return nil
+	case "ES256":
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return invalid
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(publicKey, digest[:], r, s) {
+			return invalid
+		}
+		return nil
+	}
+	return invalid
+}
+
+// newJWTClaims extracts the registered claims (and roles and scopes) from the decoded JSON.
+// It returns an error if a time claim is not a valid NumericDate.
+func newJWTClaims(raw map[string]interface{}) (*JWTClaims, error) {
+	claims := &JWTClaims{Raw: raw}
+	claims.Issuer, _ = raw["iss"].(string)
+	claims.Subject, _ = raw["sub"].(string)
+	claims.ID, _ = raw["jti"].(string)
+	claims.Audience = stringsClaim(raw["aud"])
+	for _, each := range []struct {
+		name string
+		time *time.Time
+	}{{"exp", &claims.ExpiresAt}, {"nbf", &claims.NotBefore}, {"iat", &claims.IssuedAt}} {
+		value, err := timeClaim(raw[each.name])
+		if err != nil {
+			return nil, errors.New("[restful] invalid token claim:" + each.name)
+		}
+		*each.time = value
+	}
+	claims.Roles = stringsClaim(raw["roles"])
+	if scope, ok := raw["scope"].(string); ok {
+		claims.Scopes = strings.Fields(scope)
+	} else {
+		claims.Scopes = stringsClaim(raw["scp"])
+	}
+	return claims, nil
+}
+
+// stringsClaim returns the claim value if it is a string or the strings of an array.
+func stringsClaim(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := []string{}
+		for _, each := range v {
+			if s, ok := each.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// maxNumericDate is the largest NumericDate accepted for a time claim (the year 9999).
+const maxNumericDate = 253402300799
+
+// timeClaim returns the time of a NumericDate claim (seconds since the epoch) ; zero if absent.
+// It returns an error if the value is not a number or is out of range.
+func timeClaim(value interface{}) (time.Time, error) {
+	if value == nil {
+		return time.Time{}, nil
+	}
+	seconds, ok := value.(float64)
+	if !ok || math.IsNaN(seconds) || seconds < 0 || seconds > maxNumericDate {
+		return time.Time{}, errors.New("[restful] invalid NumericDate")
+	}
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*1e9)), nil
+}
+
+// jsonWebKey holds the fields of a JWK (RFC 7517) that are needed for the supported key types.
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`   // RSA modulus
+	E       string `json:"e"`   // RSA exponent
+	Curve   string `json:"crv"` // EC curve
+	X       string `json:"x"`
+	Y       string `json:"y"`
+	K       string `json:"k"` // symmetric key
+}
+
+// parseJWKS returns the signature keys of a JWKS document by key id. Keys of unsupported types are skipped.
+func parseJWKS(data []byte) (map[string]interface{}, error) {
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	keys := map[string]interface{}{}
+	for _, each := range document.Keys {
+		if each.Use != "" && each.Use != "sig" {
+			continue
+		}
+		key, err := each.publicKey()
+		if err != nil {
+			return nil, errors.New("[restful] invalid JWK " + each.KeyID + ":" + err.Error())
+		}
+		if key != nil {
+			keys[each.KeyID] = key
+		}
+	}
+	return keys, nil
+}
+
+// publicKey returns the key for verifying signatures ; nil if the key type is not supported.
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.KeyType {
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, each := range values {
+		if each == value {
+			return true
+		}
+	}
+	return false
+}
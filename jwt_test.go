@@ -0,0 +1,177 @@
+package restful
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// signJWT returns a token with the header and claims, signed using the key for the algorithm.
+func signJWT(t *testing.T, algorithm, kid string, key interface{}, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": algorithm, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	switch algorithm {
+	case "HS256":
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case "RS256":
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTVerifier(t *testing.T) {
+	secret := []byte("s3cr3t")
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keys := NewJWTKeySet()
+	keys.AddKey("hmac", secret)
+	keys.AddKey("rsa", &rsaKey.PublicKey)
+	keys.AddKey("ec", &ecKey.PublicKey)
+	now := time.Unix(1700000000, 0)
+	verifier := NewJWTVerifier(keys)
+	verifier.Issuer, verifier.Audience, verifier.ClockSkew, verifier.now = "idp", "orders", time.Minute, func() time.Time { return now }
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		all := map[string]interface{}{"iss": "idp", "sub": "alice", "aud": []string{"orders", "users"}, "exp": now.Unix() + 10, "scope": "orders:read orders:write", "roles": []string{"user"}}
+		for k, v := range overrides {
+			all[k] = v
+		}
+		return all
+	}
+
+	for _, each := range []struct {
+		name, token string
+		valid       bool
+	}{
+		{"HS256", signJWT(t, "HS256", "hmac", secret, claims(nil)), true},
+		{"RS256", signJWT(t, "RS256", "rsa", rsaKey, claims(nil)), true},
+		{"ES256", signJWT(t, "ES256", "ec", ecKey, claims(nil)), true},
+		{"wrong secret", signJWT(t, "HS256", "hmac", []byte("other"), claims(nil)), false},
+		{"HMAC with RSA key", signJWT(t, "HS256", "rsa", secret, claims(nil)), false},
+		{"unknown kid", signJWT(t, "HS256", "missing", secret, claims(nil)), false},
+		{"none", signJWT(t, "none", "hmac", secret, claims(nil)), false},
+		{"expired within skew", signJWT(t, "HS256", "hmac", secret, claims(map[string]interface{}{"exp": now.Unix() - 30})), true},
+		{"expired", signJWT(t, "HS256", "hmac", secret, claims(map[string]interface{}{"exp": now.Unix() - 90})), false},
+		{"not before", signJWT(t, "HS256", "hmac", secret, claims(map[string]interface{}{"nbf": now.Unix() + 90})), false},
+		{"no expiration", signJWT(t, "HS256", "hmac", secret, claims(map[string]interface{}{"exp": nil})), false},
+		{"huge not before", signJWT(t, "HS256", "hmac", secret, claims(map[string]interface{}{"nbf": 1e19})), false},
+		{"expiration not a number", signJWT(t, "HS256", "hmac", secret, claims(map[string]interface{}{"exp": "tomorrow"})), false},
+		{"fractional expiration", signJWT(t, "HS256", "hmac", secret, claims(map[string]interface{}{"exp": float64(now.Unix()) + 0.5})), true},
+		{"issuer", signJWT(t, "HS256", "hmac", secret, claims(map[string]interface{}{"iss": "other"})), false},
+		{"audience", signJWT(t, "HS256", "hmac", secret, claims(map[string]interface{}{"aud": "users"})), false},
+		{"malformed", "abc.def", false},
+	} {
+		result, err := verifier.Verify(each.token)
+		if each.valid != (err == nil) {
+			t.Errorf("%s: expected valid=%v, got %v", each.name, each.valid, err)
+			continue
+		}
+		if each.valid && (result.Subject != "alice" || !result.HasScope("orders:write") || !result.HasRole("user")) {
+			t.Errorf("%s: unexpected claims:%#v", each.name, result)
+		}
+	}
+}
+
+func TestJWKSFileKeySet(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	jwks := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecKey.X.Bytes()), "y": encode(ecKey.Y.Bytes())},
+		{"kty": "RSA", "kid": "encryption", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		{"kty": "OKP", "kid": "unsupported"}}}
+	data, _ := json.Marshal(jwks)
+	dir, _ := ioutil.TempDir("", "jwks")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "jwks.json")
+	ioutil.WriteFile(path, data, 0600)
+
+	keys, err := NewJWKSFileKeySet(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys.keys) != 2 {
+		t.Fatalf("unexpected keys:%v", keys.keys)
+	}
+	verifier := &JWTVerifier{Keys: keys} // tokens without exp are accepted
+	for kid, key := range map[string]interface{}{"rsa": rsaKey, "ec": ecKey} {
+		algorithm := map[string]string{"rsa": "RS256", "ec": "ES256"}[kid]
+		if _, err := verifier.Verify(signJWT(t, algorithm, kid, key, map[string]interface{}{"sub": "bob"})); err != nil {
+			t.Errorf("%s: %v", kid, err)
+		}
+	}
+	// rotate
+	ioutil.WriteFile(path, []byte(`{"keys":[{"kty":"oct","kid":"hmac","k":"`+encode([]byte("s3cr3t"))+`"}]}`), 0600)
+	if err := keys.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := keys.key("rsa"); ok {
+		t.Error("rsa key should be removed")
+	}
+	if _, err := verifier.Verify(signJWT(t, "HS256", "", []byte("s3cr3t"), map[string]interface{}{})); err != nil {
+		t.Errorf("only key should be used without kid:%v", err)
+	}
+	ioutil.WriteFile(path, []byte(`{"keys":`), 0600)
+	if err := keys.Reload(); err == nil {
+		t.Error("expected parse error")
+	}
+	if _, ok := keys.key("hmac"); !ok {
+		t.Error("keys should be unchanged after failed reload")
+	}
+}
+
+// go test -v -test.run TestJWTFilter ...restful
+func TestJWTFilter(t *testing.T) {
+	secret := []byte("s3cr3t")
+	keys := NewJWTKeySet()
+	keys.AddKey("", secret)
+	container := NewContainer()
+	ws := new(WebService).Path("/orders").Filter(NewJWTFilter("orders", &JWTVerifier{Keys: keys}))
+	ws.Route(ws.GET("").Scopes("orders:read").To(func(req *Request, resp *Response) {
+		claims := req.Attribute(JWTClaimsAttribute).(*JWTClaims)
+		resp.Write([]byte(claims.Subject))
+	}))
+	container.Add(ws)
+
+	dispatch := func(authorization string) *httptest.ResponseRecorder {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com/orders", nil)
+		httpRequest.Header.Set("Authorization", authorization)
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+	if w := dispatch("Bearer " + signJWT(t, "HS256", "", secret, map[string]interface{}{"sub": "alice", "scp": []string{"orders:read"}})); w.Code != 200 || w.Body.String() != "alice" {
+		t.Errorf("unexpected response:%d %s", w.Code, w.Body.String())
+	}
+	if w := dispatch("Bearer " + signJWT(t, "HS256", "", secret, map[string]interface{}{"sub": "alice"})); w.Code != 403 {
+		t.Errorf("expected 403 without scope, got %d", w.Code)
+	}
+	if w := dispatch("Bearer invalid"); w.Code != 401 || w.Header().Get("WWW-Authenticate") != `Bearer realm="orders"` {
+		t.Errorf("unexpected response:%d %v", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,114 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// containerLifecycle keeps track of the dispatches in progress and whether the Container is shutting down.
+type containerLifecycle struct {
+	protection sync.Mutex
+	active     int           // number of dispatches in progress
+	draining   bool          // true once Shutdown is called
+	idle       chan struct{} // closed when draining and no dispatch is in progress
+	hooks      []func()      // called once when draining starts
+}
+
+func newContainerLifecycle() *containerLifecycle {
+	return &containerLifecycle{idle: make(chan struct{})}
+}
+
+// enter registers a new dispatch ; it returns false if the Container is draining.
+func (l *containerLifecycle) enter() bool {
+	l.protection.Lock()
+	defer l.protection.Unlock()
+	if l.draining {
+		return false
+	}
+	l.active++
+	return true
+}
+
+// leave unregisters a dispatch that was accepted by enter.
+func (l *containerLifecycle) leave() {
+	l.protection.Lock()
+	defer l.protection.Unlock()
+	l.active--
+	if l.draining && l.active == 0 {
+		l.closeIdle()
+	}
+}
+
+// drain stops accepting dispatches ; the hooks are called the first time only.
+func (l *containerLifecycle) drain() {
+	l.protection.Lock()
+	if l.draining {
+		l.protection.Unlock()
+		return
+	}
+	l.draining = true
+	if l.active == 0 {
+		l.closeIdle()
+	}
+	hooks := l.hooks
+	l.protection.Unlock()
+	for _, each := range hooks {
+		each()
+	}
+}
+
+// closeIdle closes the idle channel ; callers must hold the lock.
+func (l *containerLifecycle) closeIdle() {
+	select {
+	case <-l.idle:
+	default:
+		close(l.idle)
+	}
+}
+
+// Shutdown stops the Container from dispatching new requests and waits for the dispatches in progress to complete.
+// Requests that arrive after Shutdown is called are answered with a 503 ServiceError, written by the ServiceErrorHandler
+// after passing the container filters. Shutdown returns the error of the context if it is done before all dispatches completed.
+// Note that handlers registered using Handle are not tracked. Typically used together with http.Server.Shutdown:
+//
+//	container.Shutdown(ctx)
+//	server.Shutdown(ctx)
+func (c *Container) Shutdown(ctx context.Context) error {
+	c.lifecycle.drain()
+	select {
+	case <-c.lifecycle.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Draining returns true if Shutdown has been called on the Container.
+// Filters can use this to e.g. add a "Connection: close" header to the responses of requests still in progress.
+func (c *Container) Draining() bool {
+	c.lifecycle.protection.Lock()
+	defer c.lifecycle.protection.Unlock()
+	return c.lifecycle.draining
+}
+
+// ActiveDispatches returns the number of requests that are being dispatched by the Container.
+func (c *Container) ActiveDispatches() int {
+	c.lifecycle.protection.Lock()
+	defer c.lifecycle.protection.Unlock()
+	return c.lifecycle.active
+}
+
+// OnShutdown adds a function that is called (once) when Shutdown is called, before waiting for the dispatches in progress.
+func (c *Container) OnShutdown(hook func()) {
+	c.lifecycle.protection.Lock()
+	defer c.lifecycle.protection.Unlock()
+	c.lifecycle.hooks = append(c.lifecycle.hooks, hook)
+}
+
+// errShuttingDown is the ServiceError for requests that arrive while the Container is draining.
+var errShuttingDown = NewError(http.StatusServiceUnavailable, "503: Service Unavailable")
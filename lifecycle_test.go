@@ -0,0 +1,70 @@
+package restful
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// go test -v -test.run TestContainerShutdown ...restful
+func TestContainerShutdown(t *testing.T) {
+	container := NewContainer()
+	started, release := make(chan bool), make(chan bool)
+	ws := new(WebService).Path("/slow")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		started <- true
+		<-release
+		resp.WriteHeader(http.StatusNoContent)
+	}))
+	container.Add(ws)
+	hooked := false
+	container.OnShutdown(func() { hooked = true })
+
+	inflight := httptest.NewRecorder()
+	done := make(chan bool)
+	go func() {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com/slow", nil)
+		container.dispatch(inflight, httpRequest)
+		done <- true
+	}()
+	<-started
+	if container.ActiveDispatches() != 1 {
+		t.Fatalf("expected one active dispatch, got:%d", container.ActiveDispatches())
+	}
+
+	// shutdown cannot complete while the request is in progress
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := container.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got:%v", err)
+	}
+	if !hooked || !container.Draining() {
+		t.Error("expected draining state and hook called")
+	}
+
+	// new requests are rejected
+	rejected := httptest.NewRecorder()
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/slow", nil)
+	container.dispatch(rejected, httpRequest)
+	if rejected.Code != http.StatusServiceUnavailable || rejected.Header().Get("Connection") != "close" {
+		t.Errorf("unexpected response:%d %v", rejected.Code, rejected.Header())
+	}
+
+	close(release)
+	<-done
+	if inflight.Code != http.StatusNoContent {
+		t.Errorf("in-flight request should complete, got:%d", inflight.Code)
+	}
+	if err := container.Shutdown(context.Background()); err != nil {
+		t.Errorf("unexpected error:%v", err)
+	}
+}
+
+func TestContainerShutdownIdle(t *testing.T) {
+	container := NewContainer()
+	if err := container.Shutdown(context.Background()); err != nil {
+		t.Errorf("unexpected error:%v", err)
+	}
+}
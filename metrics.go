@@ -0,0 +1,182 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMetricsBuckets are the upper bounds (in seconds) of the request duration histogram buckets.
+var DefaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// unmatchedRoute is the route label of requests for which no Route was selected.
+const unmatchedRoute = "unmatched"
+
+// Metrics records for each Route the number of requests per status code class, a histogram of request durations
+// and the number of response bytes. Routes are identified by their Method and Path template (e.g. /users/{id}),
+// not by the request URL. Use its Filter for a Container or WebService and serve the data using its WebService.
+//
+//	metrics := restful.NewMetrics()
+//	restful.Filter(metrics.Filter)
+//	restful.Add(metrics.WebService("/metrics"))
+type Metrics struct {
+	protection sync.Mutex
+	buckets    []float64
+	routes     map[routeKey]*routeMetrics
+}
+
+// routeKey identifies a Route by its Method and Path template.
+type routeKey struct {
+	method, path string
+}
+
+// routeMetrics holds the measurements for one Route.
+type routeMetrics struct {
+	statusCounts  map[string]uint64 // key is the status code class, e.g. 2xx
+	bucketCounts  []uint64          // (non-cumulative) count for each bucket, the last one is +Inf
+	durationSum   float64           // seconds
+	count         uint64
+	responseBytes uint64
+}
+
+// NewMetrics returns a new Metrics using the buckets (in seconds, increasing) for the duration histogram.
+// If no buckets are given then DefaultMetricsBuckets is used.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultMetricsBuckets
+	}
+	return &Metrics{buckets: buckets, routes: map[routeKey]*routeMetrics{}}
+}
+
+// Filter is a FilterFunction that measures the processing of the remaining chain.
+// It uses Response.StatusCode and Response.ContentLength so data written directly to the http.ResponseWriter is not counted.
+func (m *Metrics) Filter(req *Request, resp *Response, chain *FilterChain) {
+	start := time.Now()
+	chain.ProcessFilter(req, resp)
+	path := req.SelectedRoutePath()
+	if path == "" {
+		path = unmatchedRoute
+	}
+	m.record(req.Request.Method, path, resp.StatusCode(), time.Since(start), resp.ContentLength())
+}
+
+// record adds the measurements of one request.
+func (m *Metrics) record(method, path string, status int, duration time.Duration, contentLength int) {
+	m.protection.Lock()
+	defer m.protection.Unlock()
+	key := routeKey{method, path}
+	metrics, ok := m.routes[key]
+	if !ok {
+		metrics = &routeMetrics{statusCounts: map[string]uint64{}, bucketCounts: make([]uint64, len(m.buckets)+1)}
+		m.routes[key] = metrics
+	}
+	metrics.statusCounts[strconv.Itoa(status/100)+"xx"]++
+	seconds := duration.Seconds()
+	bucket := sort.SearchFloat64s(m.buckets, seconds) // first bucket with upper bound >= seconds
+	metrics.bucketCounts[bucket]++
+	metrics.durationSum += seconds
+	metrics.count++
+	metrics.responseBytes += uint64(contentLength)
+}
+
+// WebService returns a WebService with a GET Route on the root path that serves the metrics in the Prometheus text format.
+func (m *Metrics) WebService(rootPath string) *WebService {
+	ws := new(WebService).Path(rootPath)
+	ws.Route(ws.GET("").Produces("text/plain").To(func(req *Request, resp *Response) {
+		resp.Header().Set(HEADER_ContentType, MIME_PrometheusText)
+		m.WriteTo(resp)
+	}))
+	return ws
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format, ordered by Route path and Method.
+// WriteTo implements io.WriterTo. The metrics are rendered before writing such that a slow writer does not block recording.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.protection.Lock()
+	keys := make([]routeKey, 0, len(m.routes))
+	for each := range m.routes {
+		keys = append(keys, each)
+	}
+	sort.Sort(sortableRouteKeys(keys))
+	out := new(bytes.Buffer)
+
+	out.WriteString("# HELP restful_requests_total Number of requests by Route and status code class.\n")
+	out.WriteString("# TYPE restful_requests_total counter\n")
+	for _, key := range keys {
+		classes := []string{}
+		for each := range m.routes[key].statusCounts {
+			classes = append(classes, each)
+		}
+		sort.Strings(classes)
+		for _, class := range classes {
+			writeSample(out, "restful_requests_total", key.labels()+`,status="`+class+`"`, strconv.FormatUint(m.routes[key].statusCounts[class], 10))
+		}
+	}
+	out.WriteString("# HELP restful_request_duration_seconds Duration of requests by Route.\n")
+	out.WriteString("# TYPE restful_request_duration_seconds histogram\n")
+	for _, key := range keys {
+		metrics := m.routes[key]
+		cumulative := uint64(0)
+		for i, bound := range m.buckets {
+			cumulative += metrics.bucketCounts[i]
+			writeSample(out, "restful_request_duration_seconds_bucket", key.labels()+`,le="`+formatFloat(bound)+`"`, strconv.FormatUint(cumulative, 10))
+		}
+		writeSample(out, "restful_request_duration_seconds_bucket", key.labels()+`,le="+Inf"`, strconv.FormatUint(metrics.count, 10))
+		writeSample(out, "restful_request_duration_seconds_sum", key.labels(), formatFloat(metrics.durationSum))
+		writeSample(out, "restful_request_duration_seconds_count", key.labels(), strconv.FormatUint(metrics.count, 10))
+	}
+	out.WriteString("# HELP restful_response_bytes_total Number of response body bytes by Route.\n")
+	out.WriteString("# TYPE restful_response_bytes_total counter\n")
+	for _, key := range keys {
+		writeSample(out, "restful_response_bytes_total", key.labels(), strconv.FormatUint(m.routes[key].responseBytes, 10))
+	}
+	m.protection.Unlock()
+	return out.WriteTo(w)
+}
+
+// labels returns the Prometheus labels (without braces) of the Route.
+func (k routeKey) labels() string {
+	return `method="` + escapeLabelValue(k.method) + `",route="` + escapeLabelValue(k.path) + `"`
+}
+
+func writeSample(out *bytes.Buffer, name, labels, value string) {
+	out.WriteString(name)
+	out.WriteString("{")
+	out.WriteString(labels)
+	out.WriteString("} ")
+	out.WriteString(value)
+	out.WriteString("\n")
+}
+
+// escapeLabelValue escapes backslash, double-quote and line feed as required by the exposition format.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// sortableRouteKeys sorts on path, then on method.
+type sortableRouteKeys []routeKey
+
+func (s sortableRouteKeys) Len() int {
+	return len(s)
+}
+func (s sortableRouteKeys) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+func (s sortableRouteKeys) Less(i, j int) bool {
+	if s[i].path != s[j].path {
+		return s[i].path < s[j].path
+	}
+	return s[i].method < s[j].method
+}
@@ -0,0 +1,107 @@
+package restful
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// go test -v -test.run TestMetricsFilter ...restful
+func TestMetricsFilter(t *testing.T) {
+	metrics := NewMetrics(0.1, 1)
+	container := NewContainer()
+	container.Filter(metrics.Filter)
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		if req.PathParameter("id") == "0" {
+			resp.WriteErrorString(http.StatusNotFound, "none")
+			return
+		}
+		resp.Write([]byte("ernest"))
+	}))
+	container.Add(ws)
+	container.Add(metrics.WebService("/metrics"))
+
+	for _, each := range []string{"/users/1", "/users/2", "/users/0", "/nowhere"} {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com"+each, nil)
+		container.dispatch(httptest.NewRecorder(), httpRequest)
+	}
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/metrics", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if ct := httpWriter.Header().Get(HEADER_ContentType); ct != MIME_PrometheusText {
+		t.Errorf("unexpected content-type:%s", ct)
+	}
+	body := httpWriter.Body.String()
+	for _, expected := range []string{
+		`restful_requests_total{method="GET",route="/users/{id}",status="2xx"} 2`,
+		`restful_requests_total{method="GET",route="/users/{id}",status="4xx"} 1`,
+		`restful_requests_total{method="GET",route="unmatched",status="4xx"} 1`,
+		`restful_request_duration_seconds_bucket{method="GET",route="/users/{id}",le="0.1"} 3`,
+		`restful_request_duration_seconds_bucket{method="GET",route="/users/{id}",le="+Inf"} 3`,
+		`restful_request_duration_seconds_count{method="GET",route="/users/{id}"} 3`,
+		`restful_response_bytes_total{method="GET",route="/users/{id}"} 16`,
+		"# TYPE restful_request_duration_seconds histogram",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("missing %s in:\n%s", expected, body)
+		}
+	}
+}
+
+func TestMetricsHistogram(t *testing.T) {
+	metrics := NewMetrics(0.1, 1)
+	metrics.record("PUT", `/a"b`, 201, 50*time.Millisecond, 1)
+	metrics.record("PUT", `/a"b`, 201, 500*time.Millisecond, 1)
+	metrics.record("PUT", `/a"b`, 500, 5*time.Second, 1)
+	var buffer bytes.Buffer
+	n, err := metrics.WriteTo(&buffer)
+	if err != nil || n != int64(buffer.Len()) {
+		t.Fatalf("unexpected write:%d %v", n, err)
+	}
+	for _, expected := range []string{
+		`{method="PUT",route="/a\"b",le="0.1"} 1`,
+		`{method="PUT",route="/a\"b",le="1"} 2`,
+		`{method="PUT",route="/a\"b",le="+Inf"} 3`,
+		`restful_request_duration_seconds_sum{method="PUT",route="/a\"b"} 5.55`,
+	} {
+		if !strings.Contains(buffer.String(), expected) {
+			t.Errorf("missing %s in:\n%s", expected, buffer.String())
+		}
+	}
+}
+
+// blockingWriter signals the first Write and blocks it until released.
+type blockingWriter struct {
+	writing, release chan struct{}
+}
+
+func (b *blockingWriter) Write(data []byte) (int, error) {
+	close(b.writing)
+	<-b.release
+	return len(data), nil
+}
+
+func TestMetricsWriteToDoesNotBlockRecord(t *testing.T) {
+	metrics := NewMetrics()
+	for i := 0; i < 100; i++ {
+		metrics.record("GET", "/orders/"+strings.Repeat("x", i), 200, time.Millisecond, 1)
+	}
+	writer := &blockingWriter{writing: make(chan struct{}), release: make(chan struct{})}
+	go metrics.WriteTo(writer)
+	<-writer.writing
+	recorded := make(chan struct{})
+	go func() {
+		metrics.record("GET", "/orders", 200, time.Millisecond, 1)
+		close(recorded)
+	}()
+	select {
+	case <-recorded:
+	case <-time.After(2 * time.Second):
+		t.Error("record is blocked by a slow writer")
+	}
+	close(writer.release)
+}
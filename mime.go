@@ -0,0 +1,179 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mediaRange is an element of an Accept header such as "text/*;q=0.5" or a MIME type such as "application/json".
+// http://tools.ietf.org/html/rfc7231#section-5.3.2
+type mediaRange struct {
+	mainType, subType string
+	quality           float64 // between 0 and 1 ; 0 means not acceptable
+	position          int     // index in the header, used to keep the order of equal ranges
+}
+
+// parseMediaRange parses a media range ; parameters other than the quality are ignored.
+func parseMediaRange(value string, position int) mediaRange {
+	parts := strings.Split(value, ";")
+	media := strings.ToLower(strings.TrimSpace(parts[0]))
+	m := mediaRange{mainType: media, subType: "*", quality: 1.0, position: position}
+	if slash := strings.Index(media, "/"); slash != -1 {
+		m.mainType, m.subType = media[:slash], media[slash+1:]
+	}
+	for _, param := range parts[1:] {
+		param = strings.TrimSpace(param)
+		if strings.HasPrefix(param, "q=") || strings.HasPrefix(param, "Q=") {
+			if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q >= 0 && q <= 1 {
+				m.quality = q
+			}
+		}
+	}
+	return m
+}
+
+// parseAccept returns the media ranges of an Accept header sorted by quality and specificity (highest first).
+// An empty header is the same as */*.
+func parseAccept(header string) []mediaRange {
+	if strings.TrimSpace(header) == "" {
+		return []mediaRange{{mainType: "*", subType: "*", quality: 1.0}}
+	}
+	ranges := []mediaRange{}
+	for i, each := range strings.Split(header, ",") {
+		if strings.TrimSpace(each) == "" {
+			continue
+		}
+		ranges = append(ranges, parseMediaRange(each, i))
+	}
+	sort.Sort(sortableMediaRanges(ranges))
+	return ranges
+}
+
+// specificity is 2 for n/m, 1 for n/* and 0 for */*
+func (m mediaRange) specificity() int {
+	if m.mainType == "*" {
+		return 0
+	}
+	if m.subType == "*" {
+		return 1
+	}
+	return 2
+}
+
+// matches returns whether both media ranges overlap ; wildcards are allowed on both sides.
+func (m mediaRange) matches(other mediaRange) bool {
+	if m.mainType != "*" && other.mainType != "*" && m.mainType != other.mainType {
+		return false
+	}
+	return m.subType == "*" || other.subType == "*" || m.subType == other.subType
+}
+
+// String returns the media range without parameters.
+func (m mediaRange) String() string {
+	return m.mainType + "/" + m.subType
+}
+
+// qualityOf returns the quality of the most specific range that matches the MIME type, 0 if none matches.
+// The specificity of that range is returned as well.
+func qualityOf(ranges []mediaRange, mimeType string) (quality float64, specificity int) {
+	target := parseMediaRange(mimeType, 0)
+	found := false
+	for _, each := range ranges {
+		if !each.matches(target) {
+			continue
+		}
+		if !found || each.specificity() > specificity {
+			quality, specificity, found = each.quality, each.specificity(), true
+		}
+	}
+	return quality, specificity
+}
+
+// sortableMediaRanges sorts on quality, then on specificity (both descending), then on position.
+type sortableMediaRanges []mediaRange
+
+func (s sortableMediaRanges) Len() int {
+	return len(s)
+}
+func (s sortableMediaRanges) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+func (s sortableMediaRanges) Less(i, j int) bool {
+	if s[i].quality != s[j].quality {
+		return s[i].quality > s[j].quality
+	}
+	if s[i].specificity() != s[j].specificity() {
+		return s[i].specificity() > s[j].specificity()
+	}
+	return s[i].position < s[j].position
+}
+
+// offerQuality returns the quality of an offered MIME type (e.g. a Produces of "application/xml;q=0.5") for the ranges
+// of an Accept header: that of the client multiplied by that of the server, which is 1 if the offer has no q parameter.
+// The specificity of the range that matched is returned as well.
+func offerQuality(ranges []mediaRange, offer string) (quality float64, specificity int) {
+	quality, specificity = qualityOf(ranges, offer)
+	return quality * parseMediaRange(offer, 0).quality, specificity
+}
+
+// withoutQuality returns the offered MIME type without its q parameter ; other parameters (e.g. charset) are kept.
+func withoutQuality(offer string) string {
+	if !strings.Contains(offer, ";") {
+		return offer
+	}
+	parts := strings.Split(offer, ";")
+	kept := parts[:1]
+	for _, param := range parts[1:] {
+		if name := strings.ToLower(strings.TrimSpace(param)); !strings.HasPrefix(name, "q=") {
+			kept = append(kept, param)
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, ";"))
+}
+
+// negotiatedMimeType returns the MIME type from offers that is most preferred by the Accept header.
+// The combined quality (see offerQuality) has precedence, then the specificity of the range that matched, then the order of offers.
+// An offer with a wildcard (e.g. text/* or */*) is expanded to the MIME types of the registry, with the quality of the offer.
+// Only MIME types that have an EntityReaderWriter are considered. The result has no q parameter.
+func negotiatedMimeType(accept string, offers []string, registry *entityAccessRegistry) (string, bool) {
+	ranges := parseAccept(accept)
+	best, bestQuality, bestSpecificity := "", 0.0, -1
+	for _, offer := range expandedOffers(offers, registry) {
+		if _, ok := registry.accessorAt(offer); !ok {
+			continue
+		}
+		quality, specificity := offerQuality(ranges, offer)
+		if quality > bestQuality || (quality == bestQuality && quality > 0 && specificity > bestSpecificity) {
+			best, bestQuality, bestSpecificity = offer, quality, specificity
+		}
+	}
+	return withoutQuality(best), bestQuality > 0
+}
+
+// expandedOffers replaces each wildcard MIME type by the matching MIME types of the registry.
+// The q parameter of a wildcard is given to each of its MIME types.
+func expandedOffers(offers []string, registry *entityAccessRegistry) []string {
+	expanded := []string{}
+	for _, each := range offers {
+		offer := parseMediaRange(each, 0)
+		if offer.specificity() == 2 {
+			expanded = append(expanded, each)
+			continue
+		}
+		for _, other := range registry.registeredMimeTypes() {
+			if !offer.matches(parseMediaRange(other, 0)) {
+				continue
+			}
+			if offer.quality < 1 {
+				other += ";q=" + strconv.FormatFloat(offer.quality, 'g', -1, 64)
+			}
+			expanded = append(expanded, other)
+		}
+	}
+	return expanded
+}
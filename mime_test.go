@@ -0,0 +1,82 @@
+package restful
+
+import (
+	"testing"
+)
+
+// go test -v -test.run TestParseAccept ...restful
+func TestParseAccept(t *testing.T) {
+	ranges := parseAccept("text/*;q=0.5, */*;q=0.1, application/json, application/xml;q=0.9, text/html")
+	expected := []string{"application/json", "text/html", "application/xml", "text/*", "*/*"}
+	if len(ranges) != len(expected) {
+		t.Fatalf("unexpected ranges:%v", ranges)
+	}
+	for i, each := range expected {
+		if ranges[i].String() != each {
+			t.Errorf("[%d] expected:%s, actual:%s", i, each, ranges[i])
+		}
+	}
+}
+
+func TestParseAcceptEmpty(t *testing.T) {
+	ranges := parseAccept("")
+	if len(ranges) != 1 || ranges[0].String() != "*/*" {
+		t.Errorf("expected */*, got:%v", ranges)
+	}
+}
+
+var qualities = []struct {
+	accept, mime string
+	quality      float64
+}{
+	{"application/json", "application/json", 1},
+	{"application/json;q=0.3", "application/json", 0.3},
+	{"text/*;q=0.4, */*;q=0.1", "text/plain", 0.4},
+	{"text/*;q=0.4, */*;q=0.1", "application/json", 0.1},
+	{"application/json;q=0, */*", "application/json", 0},
+	{"application/json;q=0, */*", "application/xml", 1},
+	{"application/xml", "application/json", 0},
+	{"application/json", "application/*", 1},
+}
+
+// go test -v -test.run TestQualityOf ...restful
+func TestQualityOf(t *testing.T) {
+	for i, each := range qualities {
+		if q, _ := qualityOf(parseAccept(each.accept), each.mime); q != each.quality {
+			t.Errorf("[%d] %s for %s, expected:%v, actual:%v", i, each.mime, each.accept, each.quality, q)
+		}
+	}
+}
+
+var negotiations = []struct {
+	accept   string
+	offers   []string
+	expected string
+	ok       bool
+}{
+	{"", []string{MIME_XML, MIME_JSON}, MIME_XML, true},
+	{"*/*", []string{MIME_JSON, MIME_XML}, MIME_JSON, true},
+	{"application/xml;q=0.9, application/json", []string{MIME_XML, MIME_JSON}, MIME_JSON, true},
+	{"application/xml, */*", []string{MIME_JSON, MIME_XML}, MIME_XML, true},
+	{"application/*;q=0.5, text/html", []string{MIME_JSON}, MIME_JSON, true},
+	{"application/json;q=0", []string{MIME_JSON}, "", false},
+	{"text/html", []string{MIME_JSON, MIME_XML}, "", false},
+	{"application/xml", []string{"*/*"}, MIME_XML, true},
+	{"text/html", []string{"text/html"}, "", false}, // no EntityReaderWriter
+	{"application/json, application/xml", []string{MIME_JSON + ";q=0.5", MIME_XML}, MIME_XML, true},
+	{"application/json, application/xml;q=0.4", []string{MIME_JSON + ";q=0.5", MIME_XML}, MIME_JSON, true},
+	{"", []string{MIME_XML + ";q=0.5", MIME_JSON}, MIME_JSON, true},
+	{"application/json", []string{"*/*;q=0.5"}, MIME_JSON, true},
+	{"application/json", []string{MIME_JSON + ";q=0"}, "", false},
+	{"", []string{"application/json; charset=utf-8;q=0.5"}, "application/json; charset=utf-8", true},
+}
+
+// go test -v -test.run TestNegotiatedMimeType ...restful
+func TestNegotiatedMimeType(t *testing.T) {
+	for i, each := range negotiations {
+		mime, ok := negotiatedMimeType(each.accept, each.offers, defaultEntityAccessors)
+		if ok != each.ok || mime != each.expected {
+			t.Errorf("[%d] accept:%s offers:%v, expected:%s,%v actual:%s,%v", i, each.accept, each.offers, each.expected, each.ok, mime, ok)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+)
+
+// defaultMultipartMemory is the number of bytes of a multipart body kept in memory if not set by Container.MultipartMemory.
+const defaultMultipartMemory = 32 << 20
+
+// MultipartMemory sets the maximum number of bytes of the file parts of a multipart/form-data body that are kept in memory
+// when parsing the form (see Request.FormFile) ; the remainder is spooled to temporary files, which are removed
+// when the request has been processed. Use MaxBodySize to limit the total size of the body.
+func (c *Container) MultipartMemory(bytes int64) {
+	c.multipartMemory = bytes
+}
+
+// FormParameter parses the body of a url-encoded or multipart/form-data request (once) and returns the value of the given name.
+// It returns a ServiceError with code 413 if the body exceeds the maximum size (see Container.MaxBodySize).
+func (r *Request) FormParameter(name string) (string, error) {
+	if err := r.parseForm(); err != nil {
+		return "", err
+	}
+	return r.Request.PostFormValue(name), nil
+}
+
+// FormFile parses the multipart/form-data body of the request (once) and returns the first file for the given name.
+// It returns http.ErrMissingFile if the form has no such file.
+func (r *Request) FormFile(name string) (multipart.File, *multipart.FileHeader, error) {
+	form, err := r.MultipartForm()
+	if err != nil {
+		return nil, nil, err
+	}
+	if files := form.File[name]; len(files) > 0 {
+		file, err := files[0].Open()
+		return file, files[0], err
+	}
+	return nil, nil, http.ErrMissingFile
+}
+
+// MultipartForm parses the multipart/form-data body of the request (once) and returns all its values and files.
+func (r *Request) MultipartForm() (*multipart.Form, error) {
+	if !isMultipartForm(r.Request) {
+		return nil, http.ErrNotMultipart
+	}
+	if err := r.parseForm(); err != nil {
+		return nil, err
+	}
+	return r.Request.MultipartForm, nil
+}
+
+// FormParts returns a FormPartReader to process the parts of a multipart/form-data body while it is received,
+// e.g. to store large uploads without buffering them. Reading more than maxPartSize bytes (if positive) of a part
+// fails with a ServiceError with code 413. FormParts cannot be combined with the other form methods.
+func (r *Request) FormParts(maxPartSize int64) (*FormPartReader, error) {
+	reader, err := r.Request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	return &FormPartReader{request: r.Request, reader: reader, maxPartSize: maxPartSize}, nil
+}
+
+// parseForm parses a url-encoded or multipart/form-data body into the PostForm (and MultipartForm) of the request.
+func (r *Request) parseForm() error {
+	var err error
+	if isMultipartForm(r.Request) {
+		if r.Request.MultipartForm != nil {
+			return nil
+		}
+		memory := r.multipartMemory
+		if memory <= 0 {
+			memory = defaultMultipartMemory
+		}
+		err = r.Request.ParseMultipartForm(memory)
+	} else {
+		err = r.Request.ParseForm()
+	}
+	if err != nil && bodyTooLarge(r.Request) {
+		return errBodyTooLarge
+	}
+	return err
+}
+
+// formValues returns the values of the form parameter ; for a file these are the file names.
+func (r *Request) formValues(name string) []string {
+	if r.parseForm() != nil {
+		return nil
+	}
+	if values, ok := r.Request.PostForm[name]; ok {
+		return values
+	}
+	values := []string{}
+	if r.Request.MultipartForm != nil {
+		for _, each := range r.Request.MultipartForm.File[name] {
+			values = append(values, each.Filename)
+		}
+	}
+	return values
+}
+
+// removeMultipartFiles removes the temporary files of a parsed multipart/form-data body, if any.
+func removeMultipartFiles(httpRequest *http.Request) {
+	if httpRequest.MultipartForm != nil {
+		httpRequest.MultipartForm.RemoveAll()
+	}
+}
+
+// isMultipartForm returns whether the Content-Type of the request is multipart/form-data.
+func isMultipartForm(httpRequest *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(httpRequest.Header.Get(HEADER_ContentType))
+	return err == nil && mediaType == MIME_MultipartForm
+}
+
+// FormPartReader reads the parts of a multipart/form-data body, see Request.FormParts.
+type FormPartReader struct {
+	request     *http.Request
+	reader      *multipart.Reader
+	maxPartSize int64
+}
+
+// NextPart returns the next part of the body ; io.EOF if there are no more parts.
+func (f *FormPartReader) NextPart() (*FormPart, error) {
+	part, err := f.reader.NextPart()
+	if err != nil {
+		if err != io.EOF && bodyTooLarge(f.request) {
+			return nil, errBodyTooLarge
+		}
+		return nil, err
+	}
+	formPart := &FormPart{Part: part, request: f.request}
+	if f.maxPartSize > 0 {
+		formPart.limited = &maxBodyReader{body: part, remaining: f.maxPartSize}
+	}
+	return formPart, nil
+}
+
+// FormPart is a field or file of a multipart/form-data body. Use FormName and FileName to identify it.
+type FormPart struct {
+	*multipart.Part
+	request *http.Request
+	limited *maxBodyReader // nil if there is no maximum size
+}
+
+// Read is part of io.Reader ; it fails with a ServiceError with code 413 after the maximum size of a part or body.
+func (p *FormPart) Read(data []byte) (n int, err error) {
+	if p.limited == nil {
+		n, err = p.Part.Read(data)
+	} else {
+		n, err = p.limited.Read(data)
+	}
+	if err != nil && err != io.EOF && bodyTooLarge(p.request) {
+		return n, errBodyTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,123 @@
+package restful
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// newMultipartRequest returns a POST request with a text field title and a file image.
+func newMultipartRequest(t *testing.T, boundary string, image []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.SetBoundary(boundary); err != nil {
+		t.Fatal(err)
+	}
+	writer.WriteField("title", "sunset")
+	part, _ := writer.CreateFormFile("image", "sunset.png")
+	part.Write(image)
+	writer.Close()
+	httpRequest, _ := http.NewRequest("POST", "http://here.com/images", &body)
+	httpRequest.Header.Set(HEADER_ContentType, writer.FormDataContentType())
+	return httpRequest
+}
+
+// go test -v -test.run TestMultipartForm ...restful
+func TestMultipartForm(t *testing.T) {
+	var spooled string
+	container := NewContainer()
+	container.MultipartMemory(16)
+	ws := new(WebService).Path("/images")
+	ws.Route(ws.POST("").Consumes(MIME_MultipartForm).
+		Param(ws.FormParameter("image", "").DataType("file").Required(true)).
+		Filter(ValidateParameters).
+		To(func(req *Request, resp *Response) {
+			title, _ := req.FormParameter("title")
+			file, header, err := req.FormFile("image")
+			if err != nil {
+				resp.WriteErrorString(http.StatusBadRequest, err.Error())
+				return
+			}
+			defer file.Close()
+			if onDisk, ok := file.(*os.File); ok {
+				spooled = onDisk.Name()
+			}
+			data, _ := io.ReadAll(file)
+			io.WriteString(resp, title+" "+header.Filename+" "+string(data))
+		}))
+	container.Add(ws)
+
+	// a quoted boundary may contain a comma
+	httpRequest := newMultipartRequest(t, "a,b", []byte("0123456789abcdefghij"))
+	if !strings.Contains(httpRequest.Header.Get(HEADER_ContentType), `"a,b"`) {
+		t.Fatalf("expected quoted boundary:%s", httpRequest.Header.Get(HEADER_ContentType))
+	}
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != 200 || httpWriter.Body.String() != "sunset sunset.png 0123456789abcdefghij" {
+		t.Fatalf("unexpected response:%d %s", httpWriter.Code, httpWriter.Body.String())
+	}
+	if spooled == "" {
+		t.Fatal("expected the file to be spooled to disk")
+	}
+	if _, err := os.Stat(spooled); !os.IsNotExist(err) {
+		t.Errorf("expected spooled file to be removed:%v", err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("title", "no image")
+	writer.Close()
+	httpRequest, _ = http.NewRequest("POST", "http://here.com/images", &body)
+	httpRequest.Header.Set(HEADER_ContentType, writer.FormDataContentType())
+	httpWriter = httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != 400 || !strings.Contains(httpWriter.Body.String(), "image") {
+		t.Errorf("expected 400 for missing file, got %d %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+func TestFormParts(t *testing.T) {
+	for maxPartSize, expected := range map[int64]error{0: nil, 20: nil, 10: errBodyTooLarge} {
+		request := newRequest(newMultipartRequest(t, "boundary", []byte("0123456789abcdefghij")))
+		parts, err := request.FormParts(maxPartSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		var readErr error
+		for {
+			part, err := parts.NextPart()
+			if err != nil {
+				if err != io.EOF {
+					t.Fatal(err)
+				}
+				break
+			}
+			names = append(names, part.FormName()+":"+part.FileName())
+			if _, err := io.ReadAll(part); err != nil {
+				readErr = err
+			}
+		}
+		if strings.Join(names, ",") != "title:,image:sunset.png" || readErr != expected {
+			t.Errorf("%d: unexpected parts %v %v", maxPartSize, names, readErr)
+		}
+	}
+}
+
+func TestFormParameterURLEncoded(t *testing.T) {
+	httpRequest, _ := http.NewRequest("POST", "http://here.com/profiles", strings.NewReader("name=joe&age=42"))
+	httpRequest.Header.Set(HEADER_ContentType, MIME_URLEncodedForm)
+	request := newRequest(httpRequest)
+	if name, err := request.FormParameter("name"); err != nil || name != "joe" {
+		t.Errorf("unexpected name:%s %v", name, err)
+	}
+	if _, err := request.MultipartForm(); err != http.ErrNotMultipart {
+		t.Errorf("expected ErrNotMultipart, got %v", err)
+	}
+}
@@ -20,8 +20,8 @@ func TestOptionsFilter(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
 	DefaultContainer.dispatch(httpWriter, httpRequest)
 	actual := httpWriter.Header().Get(HEADER_Allow)
-	if "GET,DELETE" != actual {
-		t.Fatal("expected: GET,DELETE but got:" + actual)
+	if "GET,DELETE,HEAD" != actual {
+		t.Fatal("expected: GET,DELETE,HEAD but got:" + actual)
 	}
 
 	httpRequest, _ = http.NewRequest("OPTIONS", "http://here.io/candies", nil)
@@ -9,10 +9,11 @@ const (
 	QUERY_PARAMETER         // indicator of Request parameter type "query"
 	BODY_PARAMETER          // indicator of Request parameter type "body"
 	HEADER_PARAMETER        // indicator of Request parameter type "header"
+	FORM_PARAMETER          // indicator of Request parameter type "form"
 )
 
 // Parameter is for documententing the parameter used in a Http Request
-// ParameterData kinds are Path,Query and Body
+// ParameterData kinds are Path,Query,Body,Header and Form
 type Parameter struct {
 	data *ParameterData
 }
@@ -55,6 +56,11 @@ func (p *Parameter) beHeader() *Parameter {
 	return p
 }
 
+func (p *Parameter) beForm() *Parameter {
+	p.data.Kind = FORM_PARAMETER
+	return p
+}
+
 // Required sets the required field and return the receiver
 func (p *Parameter) Required(required bool) *Parameter {
 	p.data.Required = required
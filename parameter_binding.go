@@ -0,0 +1,200 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parameterTags maps the struct tag names recognized by ReadParameters to the Parameter kinds.
+var parameterTags = []struct {
+	tag  string
+	kind int
+}{
+	{"path", PATH_PARAMETER},
+	{"query", QUERY_PARAMETER},
+	{"header", HEADER_PARAMETER},
+	{"form", FORM_PARAMETER},
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ReadParameters populates the fields of the struct pointed to by structPointer from the parameters of the Request.
+// The source of a field is given by its tag:
+//
+//	type ListParams struct {
+//		ID      int64     `path:"id"`
+//		Limit   int       `query:"limit" default:"10"`
+//		Tags    []string  `query:"tag"`
+//		Since   time.Time `query:"since"`
+//		TraceID string    `header:"X-Trace"`
+//		Name    string    `form:"name"`
+//	}
+//
+// Supported field types are string, bool, all int, uint and float types, time.Time (RFC 3339 or 2006-01-02),
+// pointers to these and slices of these. A slice receives all values, each split by comma.
+// A field without a value keeps its current value unless a default tag is given.
+// Embedded structs are populated as well. The form values are read from the body (once) as for FormParameter.
+// If one or more values cannot be converted then a ServiceError with status 400 is returned that lists all of them.
+// A tagged field of another type is a programming error ; it is returned as a plain error (not a ServiceError).
+func (r *Request) ReadParameters(structPointer interface{}) error {
+	target := reflect.ValueOf(structPointer)
+	if target.Kind() != reflect.Ptr || target.Elem().Kind() != reflect.Struct {
+		return errors.New("[restful] ReadParameters requires a pointer to a struct")
+	}
+	violations, err := r.readParametersInto(target.Elem())
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	messages := []string{}
+	for _, each := range violations {
+		messages = append(messages, each.Kind+" parameter "+each.Name+"="+strconv.Quote(each.Value)+" "+each.Reason)
+	}
+	return NewError(http.StatusBadRequest, strings.Join(messages, "; "))
+}
+
+// readParametersInto sets all tagged fields of the struct value and returns the values that could not be converted.
+// It returns an error if a tagged field has an unsupported type.
+func (r *Request) readParametersInto(target reflect.Value) ([]ParameterError, error) {
+	violations := []ParameterError{}
+	structType := target.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		value := target.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			embedded, err := r.readParametersInto(value)
+			if err != nil {
+				return nil, err
+			}
+			violations = append(violations, embedded...)
+			continue
+		}
+		if !value.CanSet() {
+			continue
+		}
+		for _, each := range parameterTags {
+			name := field.Tag.Get(each.tag)
+			if name == "" {
+				continue
+			}
+			if !isParameterFieldType(field.Type) {
+				return nil, errors.New("[restful] ReadParameters does not support field " + structType.String() + "." + field.Name + " of type " + field.Type.String())
+			}
+			values := r.valuesOfParameter(each.kind, name)
+			if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+				if defaultValue := field.Tag.Get("default"); defaultValue != "" {
+					values = []string{defaultValue}
+				} else {
+					continue
+				}
+			}
+			if reason := setParameterField(value, values); reason != "" {
+				violations = append(violations, ParameterError{Name: name, Kind: each.tag, Value: strings.Join(values, ","), Reason: reason})
+			}
+			break
+		}
+	}
+	return violations, nil
+}
+
+// isParameterFieldType returns whether setParameterField can assign values to a field of the type.
+func isParameterFieldType(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType == timeType {
+		return true
+	}
+	switch fieldType.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// valuesOfParameter returns all values of the parameter of the given kind.
+func (r *Request) valuesOfParameter(kind int, name string) []string {
+	_, values := parameterValues(r, kind, name)
+	return values
+}
+
+// setParameterField converts the values and assigns the result to the field.
+// It returns the reason if a value cannot be converted, empty otherwise.
+func setParameterField(field reflect.Value, values []string) string {
+	switch {
+	case field.Kind() == reflect.Slice:
+		values = splitCommaSeparated(values)
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, each := range values {
+			if reason := setParameterValue(slice.Index(i), each); reason != "" {
+				return reason
+			}
+		}
+		field.Set(slice)
+	case field.Kind() == reflect.Ptr:
+		element := reflect.New(field.Type().Elem())
+		if reason := setParameterValue(element.Elem(), values[0]); reason != "" {
+			return reason
+		}
+		field.Set(element)
+	default:
+		return setParameterValue(field, values[0])
+	}
+	return ""
+}
+
+// setParameterValue converts a single value and assigns it ; the type of the field must be checked by isParameterFieldType.
+func setParameterValue(field reflect.Value, value string) string {
+	if field.Type() == timeType {
+		moment, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if moment, err = time.Parse(dateLayout, value); err != nil {
+				return "must be a date-time (RFC 3339) or a date (" + dateLayout + ")"
+			}
+		}
+		field.Set(reflect.ValueOf(moment))
+		return ""
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return "must be a boolean"
+		}
+		field.SetBool(flag)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return "must be an integer of " + strconv.Itoa(field.Type().Bits()) + " bits"
+		}
+		field.SetInt(number)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return "must be an unsigned integer of " + strconv.Itoa(field.Type().Bits()) + " bits"
+		}
+		field.SetUint(number)
+	case reflect.Float32, reflect.Float64:
+		number, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return "must be a number"
+		}
+		field.SetFloat(number)
+	}
+	return ""
+}
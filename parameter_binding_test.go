@@ -0,0 +1,86 @@
+package restful
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type pagingParams struct {
+	Limit  int  `query:"limit" default:"10"`
+	Offset *int `query:"offset"`
+}
+
+type listParams struct {
+	pagingParams
+	ID      int64     `path:"id"`
+	Tags    []string  `query:"tag"`
+	Scores  []float32 `query:"score"`
+	Since   time.Time `query:"since"`
+	Active  bool      `query:"active"`
+	TraceID string    `header:"X-Trace"`
+	Name    string    `form:"name"`
+	Ignored string
+}
+
+func TestReadParameters(t *testing.T) {
+	body := strings.NewReader("name=ernest")
+	httpRequest, _ := http.NewRequest("POST", "/items/42?tag=a,b&tag=c&score=1.5&since=2013-11-20&active=1", body)
+	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpRequest.Header.Set("X-Trace", "abc")
+	request := newRequest(httpRequest)
+	request.pathParameters["id"] = "42"
+
+	params := listParams{Ignored: "keep"}
+	if err := request.ReadParameters(&params); err != nil {
+		t.Fatal(err)
+	}
+	if params.ID != 42 || params.Limit != 10 || params.Offset != nil || !params.Active {
+		t.Errorf("unexpected params:%#v", params)
+	}
+	if len(params.Tags) != 3 || params.Tags[2] != "c" || len(params.Scores) != 1 || params.Scores[0] != 1.5 {
+		t.Errorf("unexpected slices:%v %v", params.Tags, params.Scores)
+	}
+	if params.Since.Day() != 20 || params.TraceID != "abc" || params.Name != "ernest" || params.Ignored != "keep" {
+		t.Errorf("unexpected params:%#v", params)
+	}
+}
+
+func TestReadParameters_Errors(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/items/x?limit=many&offset=2", nil)
+	request := newRequest(httpRequest)
+	request.pathParameters["id"] = "x"
+
+	params := listParams{}
+	err := request.ReadParameters(&params)
+	serviceError, ok := err.(ServiceError)
+	if !ok || serviceError.Code != http.StatusBadRequest {
+		t.Fatalf("expected ServiceError 400, got:%v", err)
+	}
+	if !strings.Contains(serviceError.Message, `query parameter limit="many"`) || !strings.Contains(serviceError.Message, `path parameter id="x"`) {
+		t.Errorf("unexpected message:%s", serviceError.Message)
+	}
+	if params.Offset == nil || *params.Offset != 2 {
+		t.Errorf("valid values should be set:%#v", params)
+	}
+	if err := request.ReadParameters(params); err == nil {
+		t.Error("expected error for non-pointer")
+	}
+}
+
+func TestReadParameters_UnsupportedFieldType(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/items", nil)
+	request := newRequest(httpRequest)
+	params := struct {
+		pagingParams
+		Filter map[string]string `query:"filter"`
+	}{}
+	err := request.ReadParameters(&params)
+	if _, ok := err.(ServiceError); ok || err == nil {
+		t.Fatalf("expected a plain error even without a value, got:%v", err)
+	}
+	if !strings.Contains(err.Error(), "Filter") {
+		t.Errorf("unexpected message:%s", err.Error())
+	}
+}
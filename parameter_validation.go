@@ -0,0 +1,166 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParameterError describes a Request parameter value that is missing or cannot be converted to its DataType.
+type ParameterError struct {
+	Name   string // name of the parameter
+	Kind   string // path, query, header or form
+	Value  string // the value as found in the Request ; empty if missing
+	Reason string // why the value is not valid
+}
+
+// Error returns a text representation of the parameter error
+func (p ParameterError) Error() string {
+	return fmt.Sprintf("[restful] invalid %s parameter %s=%q: %s", p.Kind, p.Name, p.Value, p.Reason)
+}
+
+// ValidationError is the entity written by ValidateParameters if one or more parameters are not valid.
+type ValidationError struct {
+	Code       int
+	Message    string
+	Violations []ParameterError
+}
+
+// ValidateParameters is a filter function that checks the values of all Parameters declared on the selected Route
+// before the RouteFunction is called. For each path, query and header Parameter it checks:
+//
+//	Required        the value must be present and non-empty
+//	AllowMultiple   a query parameter cannot be repeated unless allowed ; its values can be comma separated
+//	DataType        integer,int32,int64,long,number,float,double,boolean,date or date-time values must convert
+//	AllowableValues if not empty, the value must be one of its keys
+//
+// If any check fails then the filter writes a ValidationError with status 400 and the chain is not continued.
+// Body parameters are not checked. Install it for a WebService or a Container:
+//
+//	ws.Filter(restful.ValidateParameters)
+func ValidateParameters(req *Request, resp *Response, chain *FilterChain) {
+	if req.selectedRoute == nil {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	violations := []ParameterError{}
+	for _, each := range req.selectedRoute.ParameterDocs {
+		violations = append(violations, validateParameter(req, each.Data())...)
+	}
+	if len(violations) > 0 {
+		resp.WriteHeaderAndEntity(http.StatusBadRequest, ValidationError{
+			Code:       http.StatusBadRequest,
+			Message:    "400: Invalid Parameters",
+			Violations: violations})
+		return
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// validateParameter returns all violations of the parameter values in the request.
+func validateParameter(req *Request, param ParameterData) []ParameterError {
+	kind, values := parameterValues(req, param.Kind, param.Name)
+	if kind == "" {
+		return nil
+	}
+	violation := func(value, reason string) ParameterError {
+		return ParameterError{Name: param.Name, Kind: kind, Value: value, Reason: reason}
+	}
+	if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+		if param.Required {
+			return []ParameterError{violation("", "is required")}
+		}
+		return nil
+	}
+	if param.AllowMultiple {
+		values = splitCommaSeparated(values)
+	} else if len(values) > 1 {
+		return []ParameterError{violation(strings.Join(values, ","), "cannot have multiple values")}
+	}
+	violations := []ParameterError{}
+	for _, each := range values {
+		if err := convertibleTo(param.DataType, each); err != nil {
+			violations = append(violations, violation(each, err.Error()))
+			continue
+		}
+		if len(param.AllowableValues) > 0 {
+			if _, ok := param.AllowableValues[each]; !ok {
+				violations = append(violations, violation(each, "is not one of the allowable values"))
+			}
+		}
+	}
+	return violations
+}
+
+// parameterValues returns the name of the kind and the values of the parameter in the request.
+// The query is taken from the URL only such that the body is not consumed ; form values are read from the body.
+func parameterValues(req *Request, kind int, name string) (string, []string) {
+	switch kind {
+	case PATH_PARAMETER:
+		if value, ok := req.pathParameters[name]; ok {
+			return "path", []string{value}
+		}
+		return "path", nil
+	case QUERY_PARAMETER:
+		return "query", req.Request.URL.Query()[name]
+	case HEADER_PARAMETER:
+		return "header", req.Request.Header[http.CanonicalHeaderKey(name)]
+	case FORM_PARAMETER:
+		return "form", req.formValues(name)
+	}
+	return "", nil
+}
+
+// splitCommaSeparated returns all values, each split by comma.
+func splitCommaSeparated(values []string) []string {
+	split := []string{}
+	for _, each := range values {
+		for _, part := range strings.Split(each, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				split = append(split, part)
+			}
+		}
+	}
+	return split
+}
+
+// convertibleTo returns an error if the value cannot be converted to the (Swagger) data type.
+// Unknown data types, such as string or model names, accept any value.
+func convertibleTo(dataType, value string) error {
+	var err error
+	reason := ""
+	switch strings.ToLower(dataType) {
+	case "integer", "int", "int64", "long":
+		_, err = strconv.ParseInt(value, 10, 64)
+		reason = "must be an integer"
+	case "int32":
+		_, err = strconv.ParseInt(value, 10, 32)
+		reason = "must be a 32-bit integer"
+	case "number", "float", "float32", "float64", "double":
+		_, err = strconv.ParseFloat(value, 64)
+		reason = "must be a number"
+	case "boolean", "bool":
+		_, err = strconv.ParseBool(value)
+		reason = "must be a boolean"
+	case "date":
+		_, err = time.Parse(dateLayout, value)
+		reason = "must be a date (" + dateLayout + ")"
+	case "date-time", "datetime", "time":
+		_, err = time.Parse(time.RFC3339, value)
+		reason = "must be a date-time (RFC 3339)"
+	}
+	if err != nil {
+		return errors.New(reason)
+	}
+	return nil
+}
+
+// dateLayout is the format of a parameter with DataType "date"
+const dateLayout = "2006-01-02"
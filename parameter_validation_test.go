@@ -0,0 +1,69 @@
+package restful
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newValidatedContainer() *Container {
+	container := NewContainer()
+	ws := new(WebService).Path("/items")
+	ws.Filter(ValidateParameters)
+	ws.Route(ws.GET("/{id}").To(dummy).
+		Param(ws.PathParameter("id", "identifier").DataType("integer")).
+		Param(ws.QueryParameter("sort", "sort order").AllowableValues(map[string]string{"asc": "ascending", "desc": "descending"})).
+		Param(ws.QueryParameter("tag", "tags").AllowMultiple(true).DataType("int32")).
+		Param(ws.HeaderParameter("X-Tenant", "tenant").Required(true)))
+	container.Add(ws)
+	return container
+}
+
+func TestValidateParameters_Valid(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/items/42?sort=asc&tag=1,2&tag=3", nil)
+	httpRequest.Header.Set("X-Tenant", "acme")
+	httpWriter := httptest.NewRecorder()
+	newValidatedContainer().dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != http.StatusOK {
+		t.Fatalf("unexpected status:%d %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+func TestValidateParameters_Violations(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/items/abc?sort=up&tag=1,x", nil)
+	httpRequest.Header.Set("Accept", MIME_JSON)
+	httpWriter := httptest.NewRecorder()
+	newValidatedContainer().dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status:%d", httpWriter.Code)
+	}
+	validationError := ValidationError{}
+	if err := json.Unmarshal(httpWriter.Body.Bytes(), &validationError); err != nil {
+		t.Fatal(err)
+	}
+	expected := []ParameterError{
+		{Name: "id", Kind: "path", Value: "abc", Reason: "must be an integer"},
+		{Name: "sort", Kind: "query", Value: "up", Reason: "is not one of the allowable values"},
+		{Name: "tag", Kind: "query", Value: "x", Reason: "must be a 32-bit integer"},
+		{Name: "X-Tenant", Kind: "header", Value: "", Reason: "is required"},
+	}
+	if len(validationError.Violations) != len(expected) {
+		t.Fatalf("unexpected violations:%v", validationError.Violations)
+	}
+	for i, each := range expected {
+		if validationError.Violations[i] != each {
+			t.Errorf("violation %d: got %v want %v", i, validationError.Violations[i], each)
+		}
+	}
+}
+
+func TestValidateParameters_NoMultiple(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/items/1?sort=asc&sort=desc", nil)
+	httpRequest.Header.Set("X-Tenant", "acme")
+	httpWriter := httptest.NewRecorder()
+	newValidatedContainer().dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status:%d", httpWriter.Code)
+	}
+}
@@ -6,6 +6,7 @@ package restful
 
 import (
 	"bytes"
+	"errors"
 	"regexp"
 	"strings"
 )
@@ -13,11 +14,13 @@ import (
 // PathExpression holds a compiled path expression (RegExp) needed to match against
 // Http request paths and to extract path parameter values.
 type pathExpression struct {
-	LiteralCount int // the number of literal characters (means those not resulting from template variable substitution)
-	VarCount     int // the number of named parameters (enclosed by {}) in the path
-	Matcher      *regexp.Regexp
-	Source       string // Path as defined by the RouteBuilder
-	tokens       []string
+	LiteralCount    int // the number of literal characters (means those not resulting from template variable substitution)
+	VarCount        int // the number of named parameters (enclosed by {}) in the path
+	NonDefaultCount int // the number of named parameters with a regular expression (e.g. {id:[0-9]+})
+	Matcher         *regexp.Regexp
+	Source          string // Path as defined by the RouteBuilder
+	tokens          []string
+	segmentMatchers []*regexp.Regexp // for each token the compiled constraint of its parameter ; nil if none
 }
 
 // NewPathExpression creates a PathExpression from the input URL path.
@@ -28,7 +31,16 @@ func newPathExpression(path string) (*pathExpression, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &pathExpression{literalCount, varCount, compiled, expression, tokens}, nil
+	matchers, err := compileSegmentMatchers(tokens)
+	if err != nil {
+		return nil, err
+	}
+	return &pathExpression{literalCount, varCount, nonDefaultCount(tokens), compiled, expression, tokens, matchers}, nil
+}
+
+// hasCatchAll returns whether the last token is a parameter that matches the remainder of the path.
+func (p *pathExpression) hasCatchAll() bool {
+	return len(p.tokens) > 0 && isCatchAll(p.tokens[len(p.tokens)-1])
 }
 
 // http://jsr311.java.net/nonav/releases/1.1/spec/spec3.html#x3-370003.7.3
@@ -43,9 +55,18 @@ func templateToRegularExpression(template string) (expression string, literalCou
 		}
 		buffer.WriteString("/")
 		if strings.HasPrefix(each, "{") {
-			// ignore var spec
 			varCount += 1
-			buffer.WriteString("([^/]+?)")
+			_, constraint := parsePathParameter(each)
+			switch constraint {
+			case "":
+				buffer.WriteString("([^/]+?)")
+			case catchAllConstraint:
+				// also match an empty remainder without the slash
+				buffer.Truncate(buffer.Len() - 1)
+				buffer.WriteString("(?:/(.*))?")
+			default:
+				buffer.WriteString("(" + constraint + ")")
+			}
 		} else {
 			literalCount += len(each)
 			encoded := each // TODO URI encode
@@ -54,3 +75,80 @@ func templateToRegularExpression(template string) (expression string, literalCou
 	}
 	return strings.TrimRight(buffer.String(), "/") + "(/.*)?$", literalCount, varCount, tokens
 }
+
+// catchAllConstraint is used in a template parameter that matches the remainder of the path, e.g. /files/{path:*}
+const catchAllConstraint = "*"
+
+// parsePathParameter returns the name and the (optional) constraint of a template parameter token.
+// For example, {id:[0-9]+} returns "id" and "[0-9]+".
+func parsePathParameter(token string) (name, constraint string) {
+	spec := strings.TrimSuffix(strings.TrimPrefix(token, "{"), "}")
+	colon := strings.Index(spec, ":")
+	if colon == -1 {
+		return strings.TrimSpace(spec), ""
+	}
+	return strings.TrimSpace(spec[:colon]), strings.TrimSpace(spec[colon+1:])
+}
+
+// isCatchAll returns whether the token is a parameter that matches the remainder of the path.
+func isCatchAll(token string) bool {
+	if !strings.HasPrefix(token, "{") {
+		return false
+	}
+	_, constraint := parsePathParameter(token)
+	return constraint == catchAllConstraint
+}
+
+// nonDefaultCount returns the number of parameter tokens that have a regular expression constraint.
+// A catch-all is not counted because it is less specific than a default parameter.
+func nonDefaultCount(tokens []string) (count int) {
+	for _, each := range tokens {
+		if strings.HasPrefix(each, "{") {
+			if _, constraint := parsePathParameter(each); constraint != "" && constraint != catchAllConstraint {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// compileSegmentMatchers returns for each token the compiled regular expression that a path segment must match.
+// Literals, unconstrained parameters and catch-all parameters have no matcher (nil).
+// Returns an error if a constraint is not a valid regular expression or a catch-all is not the last token.
+func compileSegmentMatchers(tokens []string) ([]*regexp.Regexp, error) {
+	matchers := make([]*regexp.Regexp, len(tokens))
+	for i, each := range tokens {
+		if !strings.HasPrefix(each, "{") {
+			continue
+		}
+		_, constraint := parsePathParameter(each)
+		switch constraint {
+		case "":
+			// any value
+		case catchAllConstraint:
+			if i != len(tokens)-1 {
+				return nil, errors.New("catch-all parameter must be the last in the path:" + each)
+			}
+		default:
+			compiled, err := regexp.Compile("^(?:" + constraint + ")$")
+			if err != nil {
+				return nil, err
+			}
+			matchers[i] = compiled
+		}
+	}
+	return matchers, nil
+}
+
+// matchesSegmentConstraints returns whether each request token satisfies the matcher at the same position (if any).
+func matchesSegmentConstraints(matchers []*regexp.Regexp, requestTokens []string) bool {
+	for i, each := range matchers {
+		if each == nil {
+			continue
+		}
+		if i >= len(requestTokens) || !each.MatchString(requestTokens[i]) {
+			return false
+		}
+	}
+	return true
+}
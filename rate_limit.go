@@ -0,0 +1,201 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitKeyFunction returns the key of the token bucket for a request, e.g. the address of the client.
+// The keys of the provided functions are prefixed by their source (ip:, route:, hdr:<name>: and attr:<name>:)
+// such that, e.g., a header value cannot take the bucket of a client address. Custom functions should use their own prefix.
+type RateLimitKeyFunction func(req *Request) string
+
+// RateLimitResult is the outcome of taking a token from a bucket.
+type RateLimitResult struct {
+	Allowed    bool          // whether a token was available
+	Remaining  int           // number of tokens left in the bucket
+	RetryAfter time.Duration // time until the next token is available ; zero if Allowed
+	Reset      time.Duration // time until the bucket is full again
+}
+
+// RateLimitStore keeps the token buckets. Implementations must be safe for concurrent use.
+// The package provides an in-memory store ; alternative stores (e.g. shared by multiple processes) can be plugged in.
+type RateLimitStore interface {
+	// Take removes one token (if available) from the bucket of the key.
+	// A bucket holds at most burst tokens and is refilled with rate tokens per second.
+	Take(key string, rate float64, burst int) RateLimitResult
+}
+
+// RateLimiter is used to create a FilterFunction that limits the number of requests using token buckets.
+// Each key (see RateLimitKeyFunction) has its own bucket. Requests that exceed the limit get a 429 ServiceError
+// with a Retry-After header. All responses get the X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers.
+//
+//	limiter := restful.NewRateLimiter(10, 20, restful.RemoteAddressKey) // 10 requests per second, bursts of 20
+//	ws.Route(ws.POST("/orders").Filter(limiter.Filter).To(createOrder))
+type RateLimiter struct {
+	Rate  float64              // tokens added per second
+	Burst int                  // maximum number of tokens
+	Key   RateLimitKeyFunction // if nil, or if it returns an empty key, then RemoteAddressKey is used
+	Store RateLimitStore
+}
+
+// NewRateLimiter returns a RateLimiter that uses a new in-memory store.
+// It panics if the rate is not positive or the burst is less than one.
+func NewRateLimiter(rate float64, burst int, key RateLimitKeyFunction) *RateLimiter {
+	limiter := &RateLimiter{Rate: rate, Burst: burst, Key: key, Store: NewMemoryRateLimitStore()}
+	if err := limiter.validate(); err != nil {
+		panic(err.Error())
+	}
+	return limiter
+}
+
+// validate returns an error if the limiter cannot be used.
+func (l *RateLimiter) validate() error {
+	if l.Rate <= 0 || math.IsNaN(l.Rate) || math.IsInf(l.Rate, 0) {
+		return fmt.Errorf("[restful] invalid RateLimiter rate:%v", l.Rate)
+	}
+	if l.Burst < 1 {
+		return fmt.Errorf("[restful] invalid RateLimiter burst:%d", l.Burst)
+	}
+	if l.Store == nil {
+		return errors.New("[restful] RateLimiter has no Store")
+	}
+	return nil
+}
+
+// Filter is a FilterFunction that takes a token for the request or responds with 429 if none is available.
+// The configuration is not checked per request ; use NewRateLimiter to have it checked when it is created.
+func (l *RateLimiter) Filter(req *Request, resp *Response, chain *FilterChain) {
+	key := ""
+	if l.Key != nil {
+		key = l.Key(req)
+	}
+	if key == "" {
+		key = RemoteAddressKey(req)
+	}
+	result := l.Store.Take(key, l.Rate, l.Burst)
+	resp.Header().Set("X-RateLimit-Limit", strconv.Itoa(l.Burst))
+	resp.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	resp.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	if !result.Allowed {
+		resp.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		resp.writeServiceError(NewError(http.StatusTooManyRequests, "429: Too Many Requests"))
+		return
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// RemoteAddressKey is a RateLimitKeyFunction that uses the host of the client (without port), e.g. ip:203.0.113.7.
+func RemoteAddressKey(req *Request) string {
+	return "ip:" + remoteHost(req.Request.RemoteAddr)
+}
+
+// RouteKey is a RateLimitKeyFunction that uses the Method and Path template of the selected Route,
+// such that all clients share the limit of a Route.
+func RouteKey(req *Request) string {
+	return "route:" + req.Request.Method + " " + req.SelectedRoutePath()
+}
+
+// HeaderKey returns a RateLimitKeyFunction that uses the value of a request header, e.g. an API key.
+func HeaderKey(name string) RateLimitKeyFunction {
+	return func(req *Request) string {
+		if value := req.HeaderParameter(name); value != "" {
+			return "hdr:" + name + ":" + value
+		}
+		return ""
+	}
+}
+
+// AttributeKey returns a RateLimitKeyFunction that uses the value of a Request attribute,
+// e.g. the authenticated principal set by a filter that runs before the RateLimiter.
+func AttributeKey(name string) RateLimitKeyFunction {
+	return func(req *Request) string {
+		if value := req.Attribute(name); value != nil {
+			return "attr:" + name + ":" + fmt.Sprint(value)
+		}
+		return ""
+	}
+}
+
+// MemoryRateLimitStore is a RateLimitStore that keeps the buckets in memory.
+// Buckets that are full again, given the rate and burst they were taken with, are removed periodically.
+type MemoryRateLimitStore struct {
+	protection sync.Mutex
+	buckets    map[string]*tokenBucket
+	takes      int              // number of takes since the last sweep
+	now        func() time.Time // to allow testing
+}
+
+// tokenBucket is the state of one bucket at the time of the last take.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	rate   float64 // of the last take, used to sweep
+	burst  int
+}
+
+// sweepInterval is the number of takes after which full buckets are removed.
+const sweepInterval = 1024
+
+// NewMemoryRateLimitStore returns an empty MemoryRateLimitStore.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: map[string]*tokenBucket{}, now: time.Now}
+}
+
+// Take is part of RateLimitStore
+func (s *MemoryRateLimitStore) Take(key string, rate float64, burst int) RateLimitResult {
+	s.protection.Lock()
+	defer s.protection.Unlock()
+	now := s.now()
+	s.takes++
+	if s.takes >= sweepInterval {
+		s.sweep(now)
+	}
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(burst), last: now}
+		s.buckets[key] = bucket
+	}
+	// refill
+	bucket.tokens = math.Min(float64(burst), bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+	bucket.last, bucket.rate, bucket.burst = now, rate, burst
+	result := RateLimitResult{}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - bucket.tokens) / rate)
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = secondsToDuration((float64(burst) - bucket.tokens) / rate)
+	return result
+}
+
+// sweep removes all buckets that are full at the given time ; callers must hold the lock.
+// Each bucket is refilled with its own rate and burst.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for key, each := range s.buckets {
+		if each.tokens+now.Sub(each.last).Seconds()*each.rate >= float64(each.burst) {
+			delete(s.buckets, key)
+		}
+	}
+	s.takes = 0
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// ceilSeconds returns the duration in whole seconds, rounded up.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,153 @@
+package restful
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	for i := 0; i < 3; i++ {
+		if result := store.Take("a", 1, 3); !result.Allowed || result.Remaining != 2-i {
+			t.Fatalf("take %d: unexpected result:%#v", i, result)
+		}
+	}
+	result := store.Take("a", 1, 3)
+	if result.Allowed || result.RetryAfter != time.Second || result.Reset != 3*time.Second {
+		t.Fatalf("unexpected result:%#v", result)
+	}
+	if !store.Take("b", 1, 3).Allowed {
+		t.Error("other key should have its own bucket")
+	}
+	now = now.Add(1500 * time.Millisecond)
+	if result := store.Take("a", 1, 3); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("expected refill:%#v", result)
+	}
+}
+
+func TestMemoryRateLimitStore_Sweep(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	store.Take("idle", 1, 1)
+	store.Take("slow", 0.001, 10)
+	now = now.Add(time.Minute)
+	for i := 0; i < sweepInterval; i++ {
+		store.Take("busy", 1000, 1)
+	}
+	if _, ok := store.buckets["idle"]; ok {
+		t.Error("full bucket should be removed")
+	}
+	if _, ok := store.buckets["slow"]; !ok {
+		t.Error("bucket that is not full at its own rate should be kept")
+	}
+}
+
+// go test -v -test.run TestRateLimiterFilter ...restful
+func TestRateLimiterFilter(t *testing.T) {
+	limiter := NewRateLimiter(0.5, 1, HeaderKey("X-Api-Key"))
+	container := NewContainer()
+	ws := new(WebService).Path("/orders")
+	ws.Route(ws.GET("").Filter(limiter.Filter).To(dummy))
+	container.Add(ws)
+
+	dispatch := func(apiKey string) *httptest.ResponseRecorder {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com/orders", nil)
+		httpRequest.RemoteAddr = "10.0.0.1:1234"
+		httpRequest.Header.Set("X-Api-Key", apiKey)
+		httpRequest.Header.Set("Accept", MIME_JSON)
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+	if first := dispatch("k1"); first.Code != http.StatusOK || first.Header().Get("X-RateLimit-Limit") != "1" || first.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Fatalf("unexpected first response:%d %v", first.Code, first.Header())
+	}
+	second := dispatch("k1")
+	if second.Code != http.StatusTooManyRequests || second.Header().Get("Retry-After") != "2" || second.Header().Get(HEADER_ContentType) != MIME_JSON {
+		t.Fatalf("unexpected second response:%d %v", second.Code, second.Header())
+	}
+	if other := dispatch("k2"); other.Code != http.StatusOK {
+		t.Errorf("other key should not be limited:%d", other.Code)
+	}
+	// empty key falls back to the remote address
+	dispatch("")
+	if fallback := dispatch(""); fallback.Code != http.StatusTooManyRequests {
+		t.Errorf("expected limit by remote address:%d", fallback.Code)
+	}
+}
+
+func TestRateLimiterFilterUsesServiceErrorHandler(t *testing.T) {
+	limiter := NewRateLimiter(0.5, 1, nil)
+	container := NewContainer()
+	container.ServiceErrorHandler(WriteProblemDetails)
+	ws := new(WebService).Path("/orders")
+	ws.Route(ws.GET("").Filter(limiter.Filter).To(dummy))
+	container.Add(ws)
+
+	var httpWriter *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com/orders", nil)
+		httpRequest.RemoteAddr = "10.0.0.1:1234"
+		httpWriter = httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+	}
+	if httpWriter.Code != http.StatusTooManyRequests || httpWriter.Header().Get(HEADER_ContentType) != MIME_ProblemJSON {
+		t.Errorf("expected problem details, got:%d %v", httpWriter.Code, httpWriter.Header())
+	}
+	if httpWriter.Header().Get("Retry-After") != "2" {
+		t.Errorf("expected Retry-After, got:%v", httpWriter.Header())
+	}
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/orders/1", nil)
+	httpRequest.RemoteAddr = "[::1]:80"
+	req := newRequest(httpRequest)
+	req.selectedRoute = &Route{Path: "/orders/{id}"}
+	req.SetAttribute("principal", "ernest")
+	if key := RemoteAddressKey(req); key != "ip:::1" {
+		t.Errorf("unexpected remote key:%s", key)
+	}
+	if key := RouteKey(req); key != "route:GET /orders/{id}" {
+		t.Errorf("unexpected route key:%s", key)
+	}
+	if key := AttributeKey("principal")(req); key != "attr:principal:ernest" {
+		t.Errorf("unexpected attribute key:%s", key)
+	}
+	httpRequest.Header.Set("X-Api-Key", "::1")
+	if key := HeaderKey("X-Api-Key")(req); key != "hdr:X-Api-Key:::1" || key == RemoteAddressKey(req) {
+		t.Errorf("unexpected header key:%s", key)
+	}
+}
+
+func TestRateLimiterValidation(t *testing.T) {
+	for _, each := range []func(){
+		func() { NewRateLimiter(0, 1, nil) },
+		func() { NewRateLimiter(1, 0, nil) },
+		func() { NewRateLimiter(math.NaN(), 1, nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic for invalid RateLimiter")
+				}
+			}()
+			each()
+		}()
+	}
+	// a nil Key falls back to the remote address
+	limiter := NewRateLimiter(1, 1, nil)
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/orders", nil)
+	httpRequest.RemoteAddr = "10.0.0.1:1234"
+	called := false
+	limiter.Filter(newRequest(httpRequest), newResponse(httptest.NewRecorder()), &FilterChain{Target: func(*Request, *Response) { called = true }})
+	if !called {
+		t.Error("expected the request to pass")
+	}
+}
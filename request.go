@@ -5,20 +5,26 @@ package restful
 // that can be found in the LICENSE file.
 
 import (
-	"encoding/json"
-	"encoding/xml"
+	"bytes"
+	"context"
 	"errors"
 	"io/ioutil"
 	"net/http"
-	"strings"
+	"strconv"
+	"time"
 )
 
 // Request is a wrapper for a http Request that provides convenience methods
 type Request struct {
 	Request        *http.Request
 	bodyContent    *[]byte // to cache the request body for multiple reads of ReadEntity
+	cacheBody      bool    // see CacheBody
 	pathParameters map[string]string
 	attributes     map[string]interface{} // for storing request-scoped values
+	accessors      *entityAccessRegistry  // if nil then the package default registry is used
+	selectedRoute  *Route                 // the Route that is dispatched to ; nil if none matched
+
+	multipartMemory int64 // see Container.MultipartMemory ; zero means the default
 }
 
 func newRequest(httpRequest *http.Request) *Request {
@@ -29,6 +35,27 @@ func newRequest(httpRequest *http.Request) *Request {
 	} // empty parameters, attributes
 }
 
+// Context returns the context of the request. It is done when the client goes away, when the request is dispatched
+// or when a filter has replaced it using SetContext and that context is done.
+func (r *Request) Context() context.Context {
+	return r.Request.Context()
+}
+
+// SetContext replaces the context of the request, e.g. to add a deadline, a tracing span or an authenticated principal.
+// Use it in a filter before calling chain.ProcessFilter ; the RouteFunction and the next filters see the new context.
+// The context should be derived from the current one such that cancellation by the Container is preserved.
+func (r *Request) SetContext(ctx context.Context) {
+	r.Request = r.Request.WithContext(ctx)
+}
+
+// SelectedRoutePath returns the Path template (e.g. /users/{id}) of the Route that is dispatched to ; empty if none.
+func (r *Request) SelectedRoutePath() string {
+	if r.selectedRoute == nil {
+		return ""
+	}
+	return r.selectedRoute.Path
+}
+
 // PathParameter accesses the Path parameter value by its name
 func (r *Request) PathParameter(name string) string {
 	return r.pathParameters[name]
@@ -44,13 +71,140 @@ func (r *Request) QueryParameter(name string) string {
 	return r.Request.FormValue(name)
 }
 
-// BodyParameter parses the body of the request (once for typically a POST or a PUT) and returns the value of the given name or an error.
-func (r *Request) BodyParameter(name string) (string, error) {
-	err := r.Request.ParseForm()
+// QueryParameters returns all Query parameter values by its name.
+// Each value is split by comma such that both ?id=1&id=2 and ?id=1,2 return [1 2] ; see Parameter.AllowMultiple.
+func (r *Request) QueryParameters(name string) []string {
+	return splitCommaSeparated(r.Request.URL.Query()[name])
+}
+
+// PathParameterInt64 returns the Path parameter value converted to an int64.
+// A ParameterError is returned if the value is missing or not an integer.
+func (r *Request) PathParameterInt64(name string) (int64, error) {
+	return parseInt64Parameter("path", name, r.pathParameters[name])
+}
+
+// PathParameterFloat64 returns the Path parameter value converted to a float64.
+// A ParameterError is returned if the value is missing or not a number.
+func (r *Request) PathParameterFloat64(name string) (float64, error) {
+	return parseFloat64Parameter("path", name, r.pathParameters[name])
+}
+
+// PathParameterBool returns the Path parameter value converted to a bool ; see QueryParameterBool.
+// A ParameterError is returned if the value is missing or not a boolean.
+func (r *Request) PathParameterBool(name string) (bool, error) {
+	return parseBoolParameter("path", name, r.pathParameters[name])
+}
+
+// PathParameterTime returns the Path parameter value converted to a time.Time using the layout.
+// If the layout is empty then time.RFC3339 is used.
+// A ParameterError is returned if the value is missing or does not match the layout.
+func (r *Request) PathParameterTime(name, layout string) (time.Time, error) {
+	return parseTimeParameter("path", name, r.pathParameters[name], layout)
+}
+
+// QueryParameterInt64 returns the (first) Query parameter value converted to an int64.
+// A ParameterError is returned if the value is missing or not an integer.
+func (r *Request) QueryParameterInt64(name string) (int64, error) {
+	return parseInt64Parameter("query", name, r.queryValue(name))
+}
+
+// queryValue returns the first value of the Query parameter. Unlike QueryParameter, it does not read the (form) body
+// such that the typed accessors agree with QueryParameters and ValidateParameters.
+func (r *Request) queryValue(name string) string {
+	return r.Request.URL.Query().Get(name)
+}
+
+// QueryParameterInt64s returns all Query parameter values converted to int64s ; see QueryParameters.
+// A ParameterError is returned for the first value that is not an integer.
+func (r *Request) QueryParameterInt64s(name string) ([]int64, error) {
+	values := r.QueryParameters(name)
+	numbers := make([]int64, 0, len(values))
+	for _, each := range values {
+		number, err := parseInt64Parameter("query", name, each)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
+
+// QueryParameterFloat64 returns the (first) Query parameter value converted to a float64.
+// A ParameterError is returned if the value is missing or not a number.
+func (r *Request) QueryParameterFloat64(name string) (float64, error) {
+	return parseFloat64Parameter("query", name, r.queryValue(name))
+}
+
+// QueryParameterBool returns the (first) Query parameter value converted to a bool.
+// Accepted values are those of strconv.ParseBool (e.g. true,false,1,0).
+// A ParameterError is returned if the value is missing or not a boolean.
+func (r *Request) QueryParameterBool(name string) (bool, error) {
+	return parseBoolParameter("query", name, r.queryValue(name))
+}
+
+// QueryParameterTime returns the (first) Query parameter value converted to a time.Time using the layout.
+// If the layout is empty then time.RFC3339 is used.
+// A ParameterError is returned if the value is missing or does not match the layout.
+func (r *Request) QueryParameterTime(name, layout string) (time.Time, error) {
+	return parseTimeParameter("query", name, r.queryValue(name), layout)
+}
+
+func parseInt64Parameter(kind, name, value string) (int64, error) {
+	if value == "" {
+		return 0, missingParameter(kind, name)
+	}
+	number, err := strconv.ParseInt(value, 10, 64)
 	if err != nil {
-		return "", err
+		return 0, ParameterError{Name: name, Kind: kind, Value: value, Reason: "must be an integer"}
 	}
-	return r.Request.PostFormValue(name), nil
+	return number, nil
+}
+
+func parseFloat64Parameter(kind, name, value string) (float64, error) {
+	if value == "" {
+		return 0, missingParameter(kind, name)
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, ParameterError{Name: name, Kind: kind, Value: value, Reason: "must be a number"}
+	}
+	return number, nil
+}
+
+func parseBoolParameter(kind, name, value string) (bool, error) {
+	if value == "" {
+		return false, missingParameter(kind, name)
+	}
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, ParameterError{Name: name, Kind: kind, Value: value, Reason: "must be a boolean"}
+	}
+	return flag, nil
+}
+
+// parseTimeParameter uses time.RFC3339 if the layout is empty.
+func parseTimeParameter(kind, name, value, layout string) (time.Time, error) {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	if value == "" {
+		return time.Time{}, missingParameter(kind, name)
+	}
+	moment, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, ParameterError{Name: name, Kind: kind, Value: value, Reason: "must match " + layout}
+	}
+	return moment, nil
+}
+
+func missingParameter(kind, name string) ParameterError {
+	return ParameterError{Name: name, Kind: kind, Reason: "is required"}
+}
+
+// BodyParameter parses the body of the request (once for typically a POST or a PUT) and returns the value of the given name or an error.
+// The body can be url-encoded or multipart, see FormParameter.
+func (r *Request) BodyParameter(name string) (string, error) {
+	return r.FormParameter(name)
 }
 
 // HeaderParameter returns the HTTP Header value of a Header name or empty if missing
@@ -58,32 +212,67 @@ func (r *Request) HeaderParameter(name string) string {
 	return r.Request.Header.Get(name)
 }
 
-// ReadEntity checks the Accept header and reads the content into the entityPointer
-// May be called multiple times in the request-response flow
+// HeaderParameterInt64 returns the (first) HTTP Header value converted to an int64.
+// A ParameterError is returned if the value is missing or not an integer.
+func (r *Request) HeaderParameterInt64(name string) (int64, error) {
+	return parseInt64Parameter("header", name, r.HeaderParameter(name))
+}
+
+// HeaderParameterFloat64 returns the (first) HTTP Header value converted to a float64.
+// A ParameterError is returned if the value is missing or not a number.
+func (r *Request) HeaderParameterFloat64(name string) (float64, error) {
+	return parseFloat64Parameter("header", name, r.HeaderParameter(name))
+}
+
+// HeaderParameterBool returns the (first) HTTP Header value converted to a bool ; see QueryParameterBool.
+// A ParameterError is returned if the value is missing or not a boolean.
+func (r *Request) HeaderParameterBool(name string) (bool, error) {
+	return parseBoolParameter("header", name, r.HeaderParameter(name))
+}
+
+// HeaderParameterTime returns the (first) HTTP Header value converted to a time.Time using the layout.
+// If the layout is empty then time.RFC3339 is used ; use http.TimeFormat for headers such as If-Modified-Since.
+// A ParameterError is returned if the value is missing or does not match the layout.
+func (r *Request) HeaderParameterTime(name, layout string) (time.Time, error) {
+	return parseTimeParameter("header", name, r.HeaderParameter(name), layout)
+}
+
+// ReadEntity checks the Content-Type header and reads the content into the entityPointer
+// using the EntityReaderWriter registered for that MIME type.
+// The body is decoded while reading it ; use CacheBody to call ReadEntity multiple times in the request-response flow.
+// If the body exceeds the maximum size (see Container.MaxBodySize) then a ServiceError with code 413 is returned.
 func (r *Request) ReadEntity(entityPointer interface{}) (err error) {
 	contentType := r.Request.Header.Get(HEADER_ContentType)
-
-	var buffer []byte
-	if r.bodyContent != nil {
-		buffer = *r.bodyContent
-	} else {
-		buffer, err = ioutil.ReadAll(r.Request.Body)
-		r.bodyContent = &buffer
+	erw, ok := r.accessors.orDefault().accessorAt(contentType)
+	if !ok {
+		return errors.New("[restful] Unable to unmarshal content of type:" + contentType)
+	}
+	if r.bodyContent == nil && !r.cacheBody {
+		// decode while reading ; the body cannot be read again
+		err = erw.Read(r.Request.Body, entityPointer)
+		if err != nil && bodyTooLarge(r.Request) {
+			return errBodyTooLarge
+		}
+		return err
+	}
+	if r.bodyContent == nil {
+		buffer, err := ioutil.ReadAll(r.Request.Body)
 		if err != nil {
+			if bodyTooLarge(r.Request) {
+				return errBodyTooLarge
+			}
 			return err
 		}
+		r.bodyContent = &buffer
 	}
+	return erw.Read(bytes.NewReader(*r.bodyContent), entityPointer)
+}
 
-	if strings.Contains(contentType, MIME_XML) {
-		err = xml.Unmarshal(buffer, entityPointer)
-	} else {
-		if strings.Contains(contentType, MIME_JSON) {
-			err = json.Unmarshal(buffer, entityPointer)
-		} else {
-			err = errors.New("[restful] Unable to unmarshal content of type:" + contentType)
-		}
-	}
-	return err
+// CacheBody makes ReadEntity keep the body in memory such that the entity can be read more than once,
+// e.g. by a filter and by the RouteFunction. Without it, ReadEntity decodes the body while reading it.
+// Call it before the first ReadEntity.
+func (r *Request) CacheBody() {
+	r.cacheBody = true
 }
 
 // SetAttribute adds or replaces the attribute with the given value.
@@ -95,3 +284,8 @@ func (r *Request) SetAttribute(name string, value interface{}) {
 func (r Request) Attribute(name string) interface{} {
 	return r.attributes[name]
 }
+
+// Principal returns the principal resolved by the Authentication filter. Returns nil if absent.
+func (r Request) Principal() interface{} {
+	return r.attributes[PrincipalAttribute]
+}
@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestPathParameter(t *testing.T) {
@@ -56,6 +57,20 @@ func TestReadEntityJsonCharset(t *testing.T) {
 	}
 }
 
+func TestReadEntityCacheBody(t *testing.T) {
+	bodyReader := strings.NewReader(`{"Value" : "42"}`)
+	httpRequest, _ := http.NewRequest("POST", "/test", bodyReader)
+	httpRequest.Header.Set("Content-Type", "application/json")
+	request := newRequest(httpRequest)
+	request.CacheBody()
+	for i := 0; i < 2; i++ {
+		sam := new(Sample)
+		if err := request.ReadEntity(sam); err != nil || sam.Value != "42" {
+			t.Fatalf("read %d failed:%v", i, err)
+		}
+	}
+}
+
 func TestBodyParameter(t *testing.T) {
 	bodyReader := strings.NewReader(`value1=42&value2=43`)
 	httpRequest, _ := http.NewRequest("POST", "/test?value1=44", bodyReader) // POST and PUT body parameters take precedence over URL query string
@@ -96,3 +111,107 @@ func TestSetAttribute(t *testing.T) {
 		t.Fatalf("missing request attribute:%v", there)
 	}
 }
+
+func TestTypedQueryParameters(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/test?limit=10&ratio=0.5&debug=true&since=2013-11-20T10:00:00Z&id=1,2&id=3&bad=x", nil)
+	request := newRequest(httpRequest)
+	if limit, err := request.QueryParameterInt64("limit"); err != nil || limit != 10 {
+		t.Errorf("limit:%v %v", limit, err)
+	}
+	if ratio, err := request.QueryParameterFloat64("ratio"); err != nil || ratio != 0.5 {
+		t.Errorf("ratio:%v %v", ratio, err)
+	}
+	if debug, err := request.QueryParameterBool("debug"); err != nil || !debug {
+		t.Errorf("debug:%v %v", debug, err)
+	}
+	if since, err := request.QueryParameterTime("since", ""); err != nil || since.Hour() != 10 {
+		t.Errorf("since:%v %v", since, err)
+	}
+	if ids, err := request.QueryParameterInt64s("id"); err != nil || len(ids) != 3 || ids[2] != 3 {
+		t.Errorf("ids:%v %v", ids, err)
+	}
+	_, err := request.QueryParameterInt64("bad")
+	if perr, ok := err.(ParameterError); !ok || perr.Value != "x" || perr.Kind != "query" {
+		t.Errorf("expected ParameterError, got:%v", err)
+	}
+	_, err = request.QueryParameterBool("missing")
+	if perr, ok := err.(ParameterError); !ok || perr.Reason != "is required" {
+		t.Errorf("expected missing ParameterError, got:%v", err)
+	}
+}
+
+func TestTypedQueryParametersIgnoreFormBody(t *testing.T) {
+	httpRequest, _ := http.NewRequest("POST", "/test?limit=10", strings.NewReader("limit=99&debug=true"))
+	httpRequest.Header.Set("Content-Type", MIME_URLEncodedForm)
+	request := newRequest(httpRequest)
+	if limit, err := request.QueryParameterInt64("limit"); err != nil || limit != 10 {
+		t.Errorf("limit:%v %v", limit, err)
+	}
+	if _, err := request.QueryParameterBool("debug"); err == nil {
+		t.Error("expected missing debug, the body is not the query")
+	}
+	if value, _ := request.FormParameter("limit"); value != "99" {
+		t.Errorf("the body should be unread, got:%q", value)
+	}
+}
+
+func TestPathParameterInt64(t *testing.T) {
+	request := newRequest(&http.Request{})
+	request.pathParameters["id"] = "42"
+	if id, err := request.PathParameterInt64("id"); err != nil || id != 42 {
+		t.Errorf("id:%v %v", id, err)
+	}
+	request.pathParameters["id"] = "forty-two"
+	if _, err := request.PathParameterInt64("id"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestTypedPathParameters(t *testing.T) {
+	request := newRequest(&http.Request{})
+	request.pathParameters["ratio"] = "0.5"
+	request.pathParameters["debug"] = "true"
+	request.pathParameters["since"] = "2013-11-20"
+	if ratio, err := request.PathParameterFloat64("ratio"); err != nil || ratio != 0.5 {
+		t.Errorf("ratio:%v %v", ratio, err)
+	}
+	if debug, err := request.PathParameterBool("debug"); err != nil || !debug {
+		t.Errorf("debug:%v %v", debug, err)
+	}
+	if since, err := request.PathParameterTime("since", "2006-01-02"); err != nil || since.Day() != 20 {
+		t.Errorf("since:%v %v", since, err)
+	}
+	_, err := request.PathParameterTime("since", "")
+	if perr, ok := err.(ParameterError); !ok || perr.Kind != "path" || perr.Reason != "must match "+time.RFC3339 {
+		t.Errorf("expected ParameterError, got:%v", err)
+	}
+}
+
+func TestTypedHeaderParameters(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/test", nil)
+	httpRequest.Header.Set("X-Limit", "10")
+	httpRequest.Header.Set("X-Ratio", "0.5")
+	httpRequest.Header.Set("X-Debug", "1")
+	httpRequest.Header.Set("If-Modified-Since", "Wed, 20 Nov 2013 10:00:00 GMT")
+	request := newRequest(httpRequest)
+	if limit, err := request.HeaderParameterInt64("x-limit"); err != nil || limit != 10 {
+		t.Errorf("limit:%v %v", limit, err)
+	}
+	if ratio, err := request.HeaderParameterFloat64("X-Ratio"); err != nil || ratio != 0.5 {
+		t.Errorf("ratio:%v %v", ratio, err)
+	}
+	if debug, err := request.HeaderParameterBool("X-Debug"); err != nil || !debug {
+		t.Errorf("debug:%v %v", debug, err)
+	}
+	if since, err := request.HeaderParameterTime("If-Modified-Since", http.TimeFormat); err != nil || since.Hour() != 10 {
+		t.Errorf("since:%v %v", since, err)
+	}
+	_, err := request.HeaderParameterInt64("X-Ratio")
+	if perr, ok := err.(ParameterError); !ok || perr.Kind != "header" || perr.Value != "0.5" {
+		t.Errorf("expected ParameterError, got:%v", err)
+	}
+	_, err = request.HeaderParameterBool("X-Missing")
+	if perr, ok := err.(ParameterError); !ok || perr.Reason != "is required" {
+		t.Errorf("expected missing ParameterError, got:%v", err)
+	}
+}
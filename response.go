@@ -5,15 +5,18 @@ package restful
 // that can be found in the LICENSE file.
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
+	"net"
 	"net/http"
-	"strings"
 )
 
 // If Accept header matching fails, fall back to this type, otherwise
 // a "406: Not Acceptable" response is returned.
-// Valid values are restful.MIME_JSON and restful.MIME_XML
+// Valid values are restful.MIME_JSON, restful.MIME_XML or any MIME type registered using RegisterEntityAccessor.
 // Example:
 // 	restful.DefaultResponseMimeType = restful.MIME_JSON
 var DefaultResponseMimeType string
@@ -22,14 +25,19 @@ var DefaultResponseMimeType string
 // It provides several convenience methods to prepare and write response content.
 type Response struct {
 	http.ResponseWriter
-	accept        string   // content-types what the Http Request says it want to receive
-	produces      []string // content-types what the Route says it can produce
-	statusCode    int      // HTTP status code that has been written explicity (if zero then net/http has written 200)
-	contentLength int      // number of bytes written for the response body
+	accept              string                // content-types what the Http Request says it want to receive
+	produces            []string              // content-types what the Route says it can produce
+	statusCode          int                   // HTTP status code that has been written explicity (if zero then net/http has written 200)
+	contentLength       int                   // number of bytes written for the response body
+	accessors           *entityAccessRegistry // if nil then the package default registry is used
+	request             *http.Request         // to evaluate conditional requests ; nil if unknown
+	computeETag         bool                  // see Container.EnableETags
+	contentEncoding     string                // negotiated by the Container (e.g. gzip) ; empty if not compressed
+	serviceErrorHandler func(ServiceError)    // the ServiceErrorHandleFunction of the Container for this request ; nil if unknown
 }
 
 func newResponse(httpWriter http.ResponseWriter) *Response {
-	return &Response{httpWriter, "", []string{}, http.StatusOK, 0} // empty content-types
+	return &Response{httpWriter, "", []string{}, http.StatusOK, 0, nil, nil, false, "", nil} // empty content-types
 }
 
 // InternalServerError writes the StatusInternalServerError header.
@@ -45,40 +53,36 @@ func (r Response) AddHeader(header string, value string) Response {
 	return r
 }
 
-// WriteEntity marshals the value using the representation denoted by the Accept Header (XML or JSON)
-// If no Accept header is specified (or */*) then return the Content-Type as specified by the first in the Route.Produces.
-// If an Accept header is specified then return the Content-Type as specified by the first in the Route.Produces that is matched with the Accept header.
-// Current implementation ignores any q-parameters in the Accept Header.
+// WriteEntity marshals the value using the representation denoted by the Accept Header.
+// The value is written by the EntityReaderWriter registered for the selected MIME type.
+// The MIME type is selected from the Route.Produces (or all registered MIME types if the Route does not specify Produces)
+// using the quality values of the Accept header multiplied by those of Route.Produces (e.g. "application/xml;q=0.5") ;
+// if qualities are equal then the most specific media range wins, followed by the order of Route.Produces.
+// If no Accept header is specified then the first in the Route.Produces with the highest quality is used.
 func (r *Response) WriteEntity(value interface{}) *Response {
-	if "" == r.accept || "*/*" == r.accept {
-		for _, each := range r.produces {
-			if MIME_JSON == each {
-				r.WriteAsJson(value)
-				return r
-			}
-			if MIME_XML == each {
-				r.WriteAsXml(value)
-				return r
-			}
-		}
-	} else { // Accept header specified ; scan for each element in Route.Produces
-		for _, each := range r.produces {
-			if strings.Index(r.accept, each) != -1 {
-				if MIME_JSON == each {
-					r.WriteAsJson(value)
-					return r
-				}
-				if MIME_XML == each {
-					r.WriteAsXml(value)
-					return r
-				}
-			}
-		}
+	return r.writeEntity(0, value)
+}
+
+// WriteHeaderAndEntity is a convenience method for writing a status and a value using the representation denoted by the Accept Header.
+// The Content-Type header is set before the status is written.
+func (r *Response) WriteHeaderAndEntity(status int, value interface{}) *Response {
+	return r.writeEntity(status, value)
+}
+
+// writeEntity writes the value using the negotiated MIME type ; the status is only written if it is non zero.
+func (r *Response) writeEntity(status int, value interface{}) *Response {
+	registry := r.accessors.orDefault()
+	produces := r.produces
+	if len(produces) == 0 {
+		produces = registry.registeredMimeTypes()
+	}
+	if mimeType, ok := negotiatedMimeType(r.accept, produces, registry); ok {
+		erw, _ := registry.accessorAt(mimeType)
+		r.writeEntityWith(status, mimeType, erw, value)
+		return r
 	}
-	if DefaultResponseMimeType == MIME_JSON {
-		r.WriteAsJson(value)
-	} else if DefaultResponseMimeType == MIME_XML {
-		r.WriteAsXml(value)
+	if erw, ok := registry.accessorAt(DefaultResponseMimeType); ok && DefaultResponseMimeType != "" {
+		r.writeEntityWith(status, DefaultResponseMimeType, erw, value)
 	} else {
 		r.WriteHeader(http.StatusNotAcceptable)
 		r.Write([]byte("406: Not Acceptable"))
@@ -86,6 +90,25 @@ func (r *Response) WriteEntity(value interface{}) *Response {
 	return r
 }
 
+// writeEntityWith encodes the value using the EntityReaderWriter and writes it with the given Content-Type.
+// The output is buffered such that an encoding failure can still be reported as a 500.
+// The status is only written if it is non zero.
+func (r *Response) writeEntityWith(status int, mimeType string, erw EntityReaderWriter, value interface{}) {
+	var buffer bytes.Buffer
+	if err := erw.Write(&buffer, value); err != nil {
+		r.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+	if r.computeETag && (status == 0 || status == http.StatusOK) && !r.checkEntityPreconditions(buffer.Bytes()) {
+		return
+	}
+	r.Header().Set(HEADER_ContentType, mimeType)
+	if status != 0 {
+		r.WriteHeader(status)
+	}
+	r.Write(buffer.Bytes())
+}
+
 // WriteAsXml is a convenience method for writing a value in xml (requires Xml tags on the value)
 func (r *Response) WriteAsXml(value interface{}) *Response {
 	output, err := xml.MarshalIndent(value, " ", " ")
@@ -119,9 +142,17 @@ func (r *Response) WriteError(httpStatus int, err error) *Response {
 
 // WriteServiceError is a convenience method for a responding with a ServiceError and a status
 func (r *Response) WriteServiceError(httpStatus int, err ServiceError) *Response {
-	r.WriteHeader(httpStatus)
-	r.WriteEntity(err)
-	return r
+	return r.writeEntity(httpStatus, err)
+}
+
+// writeServiceError writes the ServiceError using the ServiceErrorHandleFunction of the Container, if known,
+// such that errors of filters are written as those of the Container (e.g. see WriteProblemDetails).
+func (r *Response) writeServiceError(serviceError ServiceError) {
+	if r.serviceErrorHandler != nil {
+		r.serviceErrorHandler(serviceError)
+		return
+	}
+	r.WriteServiceError(serviceError.Code, serviceError)
 }
 
 // WriteErrorString is a convenience method for an error status with the actual error
@@ -154,6 +185,33 @@ func (r *Response) Write(bytes []byte) (int, error) {
 	return written, err
 }
 
+// Flush sends any buffered data to the client, e.g. for streaming or long-polling responses.
+// Flush is part of http.Flusher interface ; it does nothing if the underlying ResponseWriter does not support it.
+func (r *Response) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// CloseNotify is part of http.CloseNotifier interface.
+// If the underlying ResponseWriter does not support it then the returned channel never receives.
+// Prefer the Done channel of the request context.
+func (r *Response) CloseNotify() <-chan bool {
+	if notifier, ok := r.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+// Hijack lets the caller take over the connection.
+// Hijack is part of http.Hijacker interface ; it fails if the underlying ResponseWriter does not support it.
+func (r *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.New("[restful] ResponseWriter does not implement http.Hijacker")
+}
+
 // ContentLength returns the number of bytes written for the response content.
 // Note that this value is only correct if all data is written through the Response using its Write* methods.
 // Data written directly using the underlying http.ResponseWriter is not accounted for.
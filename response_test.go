@@ -8,7 +8,7 @@ import (
 
 func TestWriteHeader(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "*/*", []string{"*/*"}, 0, 0}
+	resp := Response{ResponseWriter: httpWriter, accept: "*/*", produces: []string{"*/*"}}
 	resp.WriteHeader(123)
 	if resp.StatusCode() != 123 {
 		t.Errorf("Unexpected status code:%d", resp.StatusCode())
@@ -17,7 +17,7 @@ func TestWriteHeader(t *testing.T) {
 
 func TestNoWriteHeader(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "*/*", []string{"*/*"}, 0, 0}
+	resp := Response{ResponseWriter: httpWriter, accept: "*/*", produces: []string{"*/*"}}
 	if resp.StatusCode() != http.StatusOK {
 		t.Errorf("Unexpected status code:%d", resp.StatusCode())
 	}
@@ -30,7 +30,7 @@ type food struct {
 // go test -v -test.run TestMeasureContentLengthXml ...restful
 func TestMeasureContentLengthXml(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "*/*", []string{"*/*"}, 0, 0}
+	resp := Response{ResponseWriter: httpWriter, accept: "*/*", produces: []string{"*/*"}}
 	resp.WriteAsXml(food{"apple"})
 	if resp.ContentLength() != 76 {
 		t.Errorf("Incorrect measured length:%d", resp.ContentLength())
@@ -40,7 +40,7 @@ func TestMeasureContentLengthXml(t *testing.T) {
 // go test -v -test.run TestMeasureContentLengthJson ...restful
 func TestMeasureContentLengthJson(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "*/*", []string{"*/*"}, 0, 0}
+	resp := Response{ResponseWriter: httpWriter, accept: "*/*", produces: []string{"*/*"}}
 	resp.WriteAsJson(food{"apple"})
 	if resp.ContentLength() != 22 {
 		t.Errorf("Incorrect measured length:%d", resp.ContentLength())
@@ -50,9 +50,29 @@ func TestMeasureContentLengthJson(t *testing.T) {
 // go test -v -test.run TestMeasureContentLengthWriteErrorString ...restful
 func TestMeasureContentLengthWriteErrorString(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "*/*", []string{"*/*"}, 0, 0}
+	resp := Response{ResponseWriter: httpWriter, accept: "*/*", produces: []string{"*/*"}}
 	resp.WriteErrorString(404, "Invalid")
 	if resp.ContentLength() != len("Invalid") {
 		t.Errorf("Incorrect measured length:%d", resp.ContentLength())
 	}
 }
+
+// go test -v -test.run TestWriteEntityQuality ...restful
+func TestWriteEntityQuality(t *testing.T) {
+	httpWriter := httptest.NewRecorder()
+	resp := Response{ResponseWriter: httpWriter, accept: "application/json;q=0.8, application/xml", produces: []string{MIME_JSON, MIME_XML}}
+	resp.WriteEntity(food{"apple"})
+	if ct := httpWriter.Header().Get(HEADER_ContentType); ct != MIME_XML {
+		t.Errorf("expected xml, got:%s", ct)
+	}
+}
+
+// go test -v -test.run TestWriteEntityNotAcceptable ...restful
+func TestWriteEntityNotAcceptable(t *testing.T) {
+	httpWriter := httptest.NewRecorder()
+	resp := Response{ResponseWriter: httpWriter, accept: "application/json;q=0, text/html", produces: []string{MIME_JSON}}
+	resp.WriteEntity(food{"apple"})
+	if httpWriter.Code != http.StatusNotAcceptable {
+		t.Errorf("expected 406, got:%d", httpWriter.Code)
+	}
+}
@@ -5,8 +5,12 @@ package restful
 // that can be found in the LICENSE file.
 
 import (
+	"log"
+	"mime"
 	"net/http"
+	"regexp"
 	"strings"
+	"time"
 )
 
 // RouteFunction declares the signature of a function that can be bound to a Route.
@@ -20,22 +24,44 @@ type Route struct {
 	Path     string // webservice root path + described path
 	Function RouteFunction
 	Filters  []FilterFunction
+	Timeout  time.Duration // zero means the timeout of the WebService or Container applies ; negative means none
+	Security []string      // names of the authentication schemes that apply, see Authentication
+	Roles    []string      // the principal must have one of these, see AuthorizationFilter
+	Scopes   []string      // the principal must have all of these, see AuthorizationFilter
+
+	MaxBodySize int64 // bytes ; zero means the maximum of the WebService or Container applies
+	WebSocket   bool  // the Function upgrades the connection, see RouteBuilder.ToWebSocket ; no timeout or content encoding applies
 
 	// cached values for dispatching
 	relativePath string
 	pathParts    []string
-	pathExpr     *pathExpression // cached compilation of relativePath as RegExp
+	pathMatchers []*regexp.Regexp      // for each of pathParts, the constraint of a parameter (if any)
+	pathExpr     *pathExpression       // cached compilation of relativePath as RegExp
+	accessors    *entityAccessRegistry // set when the WebService is added to a Container
 
 	// documentation
 	Doc                     string
 	Operation               string
 	ParameterDocs           []*Parameter
 	ReadSample, WriteSample interface{} // structs that model an example request or response payload
+	ResponseErrors          map[int]ResponseError
+}
+
+// ResponseError documents a response (error or regular) of a Route: its status code, a message and an optional model.
+type ResponseError struct {
+	Code    int
+	Message string
+	Model   interface{} // sample of the entity written for this status ; nil if none
 }
 
 // Initialize for Route
 func (r *Route) postBuild() {
 	r.pathParts = tokenizePath(r.Path)
+	matchers, err := compileSegmentMatchers(r.pathParts)
+	if err != nil {
+		log.Fatalf("[restful] Invalid path:%s because:%v", r.Path, err)
+	}
+	r.pathMatchers = matchers
 }
 
 // Create Request and Response from their http versions
@@ -43,9 +69,13 @@ func (r *Route) wrapRequestResponse(httpWriter http.ResponseWriter, httpRequest
 	params := r.extractParameters(httpRequest.URL.Path)
 	wrappedRequest := newRequest(httpRequest)
 	wrappedRequest.pathParameters = params
+	wrappedRequest.accessors = r.accessors
+	wrappedRequest.selectedRoute = r
 	wrappedResponse := newResponse(httpWriter)
 	wrappedResponse.accept = httpRequest.Header.Get(HEADER_Accept)
 	wrappedResponse.produces = r.Produces
+	wrappedResponse.accessors = r.accessors
+	wrappedResponse.request = httpRequest
 	return wrappedRequest, wrappedResponse
 }
 
@@ -60,49 +90,61 @@ func (r *Route) dispatchWithFilters(wrappedRequest *Request, wrappedResponse *Re
 	}
 }
 
+// producibleTypes returns the Produces or, if not specified, all MIME types that have an EntityReaderWriter.
+func (r Route) producibleTypes() []string {
+	if len(r.Produces) > 0 {
+		return r.Produces
+	}
+	return r.accessors.orDefault().registeredMimeTypes()
+}
+
+// consumableTypes returns the Consumes or, if not specified, all MIME types that have an EntityReaderWriter.
+func (r Route) consumableTypes() []string {
+	if len(r.Consumes) > 0 {
+		return r.Consumes
+	}
+	return r.accessors.orDefault().registeredMimeTypes()
+}
+
 // Return whether the mimeType matches to what this Route can produce.
+// The Accept header may contain wildcards (e.g. text/*) and quality parameters ; a quality of 0 excludes a MIME type.
 func (r Route) matchesAccept(mimeTypesWithQuality string) bool {
-	parts := strings.Split(mimeTypesWithQuality, ",")
-	for _, each := range parts {
-		var withoutQuality string
-		if strings.Contains(each, ";") {
-			withoutQuality = strings.Split(each, ";")[0]
-		} else {
-			withoutQuality = each
-		}
-		// trim before compare
-		withoutQuality = strings.Trim(withoutQuality, " ")
-		if withoutQuality == "*/*" {
+	if mimeTypesWithQuality == "*/*" {
+		return true
+	}
+	ranges := parseAccept(mimeTypesWithQuality)
+	for _, each := range r.producibleTypes() {
+		if quality, _ := offerQuality(ranges, each); quality > 0 {
 			return true
 		}
-		for _, other := range r.Produces {
-			if other == withoutQuality {
-				return true
-			}
-		}
 	}
 	return false
 }
 
 // Return whether the mimeType matches to what this Route can consume.
+// Consumes may contain wildcards such as */* or text/*.
 func (r Route) matchesContentType(mimeTypes string) bool {
-	parts := strings.Split(mimeTypes, ",")
-	for _, each := range parts {
-		var contentType string
-		if strings.Contains(each, ";") {
-			contentType = strings.Split(each, ";")[0]
-		} else {
-			contentType = each
-		}
-		// trim before compare
-		contentType = strings.Trim(contentType, " ")
-		for _, other := range r.Consumes {
-			if other == "*/*" || other == contentType {
-				return true
+	return r.consumesSpecificity(mimeTypes) >= 0
+}
+
+// consumesSpecificity returns the specificity (see mediaRange) of the most specific Consumes that matches the mimeType.
+// Returns -1 if none matches.
+func (r Route) consumesSpecificity(mimeTypes string) int {
+	if mediaType, _, err := mime.ParseMediaType(mimeTypes); err == nil {
+		// a single MIME type ; its parameters, such as a multipart boundary, may contain commas
+		mimeTypes = mediaType
+	}
+	specificity := -1
+	for _, each := range strings.Split(mimeTypes, ",") {
+		contentType := parseMediaRange(each, 0)
+		for _, other := range r.consumableTypes() {
+			consumes := parseMediaRange(other, 0)
+			if consumes.matches(contentType) && consumes.specificity() > specificity {
+				specificity = consumes.specificity()
 			}
 		}
 	}
-	return false
+	return specificity
 }
 
 // Extract the parameters from the request url path
@@ -117,7 +159,11 @@ func (r Route) extractParameters(urlPath string) map[string]string {
 			value = urlParts[i]
 		}
 		if strings.HasPrefix(key, "{") { // path-parameter
-			pathParameters[strings.Trim(key, "{}")] = value
+			if isCatchAll(key) && i < len(urlParts) {
+				value = strings.Join(urlParts[i:], "/")
+			}
+			name, _ := parsePathParameter(key)
+			pathParameters[name] = value
 		}
 	}
 	return pathParameters
@@ -8,6 +8,7 @@ import (
 	"log"
 	"reflect"
 	"strings"
+	"time"
 )
 
 // RouteBuilder is a helper to construct Routes.
@@ -19,17 +20,25 @@ type RouteBuilder struct {
 	httpMethod  string        // required
 	function    RouteFunction // required
 	filters     []FilterFunction
+	timeout     time.Duration
+	maxBodySize int64
+	webSocket   bool
+	security    []string
+	roles       []string
+	scopes      []string
 	// documentation
 	doc                     string
 	operation               string
 	readSample, writeSample interface{}
 	parameters              []*Parameter
+	errorMap                map[int]ResponseError
 }
 
 // To bind the route to a function.
 // If this route is matched with the incoming Http Request then call this function with the *Request,*Response pair. Required.
 func (b *RouteBuilder) To(function RouteFunction) *RouteBuilder {
 	b.function = function
+	b.webSocket = false
 	return b
 }
 
@@ -40,6 +49,7 @@ func (b *RouteBuilder) Method(method string) *RouteBuilder {
 }
 
 // Produces specifies what MIME types can be produced ; the matched one will appear in the Content-Type Http header.
+// A MIME type can have a q parameter (e.g. "application/xml;q=0.5") to lower its preference ; see Response.WriteEntity.
 func (b *RouteBuilder) Produces(mimeTypes ...string) *RouteBuilder {
 	b.produces = mimeTypes
 	return b
@@ -91,6 +101,17 @@ func (b *RouteBuilder) Param(parameter *Parameter) *RouteBuilder {
 	return b
 }
 
+// Returns allows you to document what responses (errors or regular) can be expected.
+// The model is optional ; it is a sample of the entity that is written for that status (e.g. a ServiceError).
+// Calling Returns again with the same code replaces the previous documentation.
+func (b *RouteBuilder) Returns(code int, message string, model interface{}) *RouteBuilder {
+	if b.errorMap == nil {
+		b.errorMap = map[int]ResponseError{}
+	}
+	b.errorMap[code] = ResponseError{Code: code, Message: message, Model: model}
+	return b
+}
+
 // Operation allows you to document what the acutal method/function call is of the Route.
 func (b *RouteBuilder) Operation(name string) *RouteBuilder {
 	b.operation = name
@@ -102,6 +123,47 @@ func (b *RouteBuilder) servicePath(path string) *RouteBuilder {
 	return b
 }
 
+// Timeout sets the maximum duration of calling the filters and the function of the Route to build.
+// It overrides the timeout of the WebService and the Container. A negative timeout means none, see NoTimeout.
+func (b *RouteBuilder) Timeout(timeout time.Duration) *RouteBuilder {
+	b.timeout = timeout
+	return b
+}
+
+// NoTimeout disables the timeout of the WebService and the Container for the Route to build,
+// e.g. for a long-lived stream (see Response.StartEventStream and Response.StreamNDJSON).
+func (b *RouteBuilder) NoTimeout() *RouteBuilder {
+	return b.Timeout(-1)
+}
+
+// MaxBodySize sets the maximum number of bytes of the request body for the Route to build.
+// It overrides the maximum of the WebService and the Container.
+func (b *RouteBuilder) MaxBodySize(bytes int64) *RouteBuilder {
+	b.maxBodySize = bytes
+	return b
+}
+
+// Security declares the names of the authentication schemes (see Authenticator) that apply to the Route to build.
+// It overrides the declaration of the WebService.
+func (b *RouteBuilder) Security(schemeNames ...string) *RouteBuilder {
+	b.security = schemeNames
+	return b
+}
+
+// Roles declares that the principal of a request must have one of the roles (see RoleHolder) for the Route to build.
+// It overrides the declaration of the WebService.
+func (b *RouteBuilder) Roles(roles ...string) *RouteBuilder {
+	b.roles = roles
+	return b
+}
+
+// Scopes declares that the principal of a request must have all of the (OAuth) scopes (see ScopeHolder) for the Route to build.
+// It overrides the declaration of the WebService.
+func (b *RouteBuilder) Scopes(scopes ...string) *RouteBuilder {
+	b.scopes = scopes
+	return b
+}
+
 // Filter appends a FilterFunction to the end of filters for this Route to build.
 func (b *RouteBuilder) Filter(filter FilterFunction) *RouteBuilder {
 	b.filters = append(b.filters, filter)
@@ -129,20 +191,32 @@ func (b *RouteBuilder) Build() Route {
 	if b.function == nil {
 		log.Fatalf("[restful] No function specified for route:" + b.currentPath)
 	}
+	filters := b.filters
+	if len(b.roles) > 0 || len(b.scopes) > 0 {
+		// enforce after all other filters (e.g. authentication)
+		filters = append(append([]FilterFunction{}, b.filters...), AuthorizationFilter)
+	}
 	route := Route{
-		Method:        b.httpMethod,
-		Path:          concatPath(b.rootPath, b.currentPath),
-		Produces:      b.produces,
-		Consumes:      b.consumes,
-		Function:      b.function,
-		Filters:       b.filters,
-		relativePath:  b.currentPath,
-		pathExpr:      pathExpr,
-		Doc:           b.doc,
-		Operation:     b.operation,
-		ParameterDocs: b.parameters,
-		ReadSample:    b.readSample,
-		WriteSample:   b.writeSample}
+		Method:         b.httpMethod,
+		Path:           concatPath(b.rootPath, b.currentPath),
+		Produces:       b.produces,
+		Consumes:       b.consumes,
+		Function:       b.function,
+		Filters:        filters,
+		Timeout:        b.timeout,
+		MaxBodySize:    b.maxBodySize,
+		WebSocket:      b.webSocket,
+		Security:       b.security,
+		Roles:          b.roles,
+		Scopes:         b.scopes,
+		relativePath:   b.currentPath,
+		pathExpr:       pathExpr,
+		Doc:            b.doc,
+		Operation:      b.operation,
+		ParameterDocs:  b.parameters,
+		ReadSample:     b.readSample,
+		WriteSample:    b.writeSample,
+		ResponseErrors: b.errorMap}
 	route.postBuild()
 	return route
 }
@@ -50,3 +50,19 @@ func TestRouteBuilder(t *testing.T) {
 		t.Error("consumes invalid")
 	}
 }
+
+func TestRouteBuilder_Returns(t *testing.T) {
+	b := new(RouteBuilder)
+	b.To(dummy)
+	b.Returns(404, "not found", nil).Returns(409, "conflict", ServiceError{}).Returns(404, "missing", nil)
+	r := b.Build()
+	if len(r.ResponseErrors) != 2 {
+		t.Fatalf("unexpected responses:%v", r.ResponseErrors)
+	}
+	if r.ResponseErrors[404].Message != "missing" {
+		t.Error("404 should be replaced")
+	}
+	if _, ok := r.ResponseErrors[409].Model.(ServiceError); !ok || r.ResponseErrors[409].Code != 409 {
+		t.Error("409 model invalid")
+	}
+}
@@ -31,6 +31,17 @@ func TestMatchesAcceptXml(t *testing.T) {
 	}
 }
 
+// accept with quality 0 should not match
+func TestMatchesAcceptQualityZero(t *testing.T) {
+	r := Route{Produces: []string{"application/xml"}}
+	if r.matchesAccept("application/xml;q=0, */*;q=0.5") {
+		t.Errorf("accept should not match xml with q=0")
+	}
+	if !r.matchesAccept("application/*;q=0.5") {
+		t.Errorf("accept should match application/*")
+	}
+}
+
 // content type should match consumes
 func TestMatchesContentTypeXml(t *testing.T) {
 	r := Route{Consumes: []string{"application/xml"}}
@@ -50,6 +61,17 @@ func TestMatchesContentTypeCharsetInformation(t *testing.T) {
 	}
 }
 
+// content type should match consumes with a wildcard
+func TestMatchesContentTypeWildcard(t *testing.T) {
+	r := Route{Consumes: []string{"text/*"}}
+	if !r.matchesContentType("text/plain") {
+		t.Errorf("text/* should match text/plain")
+	}
+	if r.matchesContentType("application/json") {
+		t.Errorf("text/* should not match json")
+	}
+}
+
 func TestMatchesPath_OneParam(t *testing.T) {
 	params := doExtractParams("/from/{source}", 2, "/from/here", t)
 	if params["source"] != "here" {
@@ -92,6 +114,20 @@ func TestExtractParameters_EmptyValue(t *testing.T) {
 	}
 }
 
+func TestExtractParameters_Constraint(t *testing.T) {
+	params := doExtractParams("/users/{id:[0-9]+}", 2, "/users/42", t)
+	if params["id"] != "42" {
+		t.Errorf("parameter mismatch id:%v", params)
+	}
+}
+
+func TestExtractParameters_CatchAll(t *testing.T) {
+	params := doExtractParams("/files/{path:*}", 2, "/files/a/b/c.txt", t)
+	if params["path"] != "a/b/c.txt" {
+		t.Errorf("parameter mismatch path:%v", params)
+	}
+}
+
 func TestTokenizePath(t *testing.T) {
 	if len(tokenizePath("/")) != 0 {
 		t.Errorf("not empty path tokens")
@@ -15,3 +15,9 @@ type RouteSelector interface {
 		webServices []*WebService,
 		httpRequest *http.Request) (selectedService *WebService, selected *Route, err error)
 }
+
+// routeCompiler is implemented by a RouteSelector that caches data structures for the Routes.
+// The Container that uses it calls invalidateRoutes whenever a WebService, or a Route of one of its WebServices, is added.
+type routeCompiler interface {
+	invalidateRoutes()
+}
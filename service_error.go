@@ -4,7 +4,11 @@ package restful
 // Use of this source code is governed by a license
 // that can be found in the LICENSE file.
 
-import "fmt"
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
 
 // ServiceError is a transport object to pass information about a non-Http error occurred in a WebService while processing a request.
 type ServiceError struct {
@@ -21,3 +25,34 @@ func NewError(code int, message string) ServiceError {
 func (s ServiceError) Error() string {
 	return fmt.Sprintf("[ServiceError:%v] %v", s.Code, s.Message)
 }
+
+// ProblemDetails is the representation of a ServiceError as specified by RFC 7807 (http://tools.ietf.org/html/rfc7807).
+type ProblemDetails struct {
+	XMLName  xml.Name `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type     string   `json:"type" xml:"type"`
+	Title    string   `json:"title" xml:"title"`
+	Status   int      `json:"status" xml:"status"`
+	Detail   string   `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance string   `json:"instance,omitempty" xml:"instance,omitempty"`
+}
+
+// WriteProblemDetails is a ServiceErrorHandleFunction that writes the ServiceError as RFC 7807 problem details.
+// It uses MIME_ProblemXML if the Accept header prefers it over MIME_ProblemJSON.
+//
+//	container.ServiceErrorHandler(restful.WriteProblemDetails)
+func WriteProblemDetails(serviceError ServiceError, req *Request, resp *Response) {
+	problem := ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(serviceError.Code),
+		Status:   serviceError.Code,
+		Detail:   serviceError.Message,
+		Instance: req.Request.URL.RequestURI()}
+	ranges := parseAccept(req.Request.Header.Get(HEADER_Accept))
+	jsonQuality, _ := qualityOf(ranges, MIME_ProblemJSON)
+	xmlQuality, _ := qualityOf(ranges, MIME_ProblemXML)
+	if xmlQuality > jsonQuality {
+		resp.writeEntityWith(serviceError.Code, MIME_ProblemXML, entityXMLAccess{}, problem)
+		return
+	}
+	resp.writeEntityWith(serviceError.Code, MIME_ProblemJSON, entityJSONAccess{}, problem)
+}
@@ -0,0 +1,167 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errEventStreamClosed is returned when sending to a closed EventStream.
+var errEventStreamClosed = errors.New("[restful] event stream is closed")
+
+// ServerSentEvent is a message of an EventStream. Only Data is required.
+type ServerSentEvent struct {
+	ID    string        // sent back by the client in the Last-Event-ID header when reconnecting
+	Event string        // the event type ; empty means "message"
+	Data  string        // may contain newlines
+	Retry time.Duration // reconnection time for the client ; zero means not sent
+}
+
+// EventStream writes Server-Sent Events (text/event-stream) to a Response.
+// It is safe to use from multiple goroutines. Send fails once the client has gone away.
+//
+//	stream := resp.StartEventStream()
+//	defer stream.Close()
+//	stream.Heartbeat(15 * time.Second)
+//	for {
+//		select {
+//		case status := <-job.Updates():
+//			stream.Send(ServerSentEvent{ID: status.Version, Event: "status", Data: status.String()})
+//		case <-stream.Done():
+//			return
+//		}
+//	}
+type EventStream struct {
+	response   *Response
+	ctx        context.Context // of the request
+	protection sync.Mutex
+	closed     bool
+	stop       chan struct{} // closed by Close to end the heartbeat
+}
+
+// StartEventStream sets the headers for Server-Sent Events, writes the status 200 and flushes the Response.
+// The stream must be closed (before the RouteFunction returns) using Close.
+// The timeout of the Route (see Container.Timeout) ends the stream ; build the Route with RouteBuilder.NoTimeout to keep it open.
+func (r *Response) StartEventStream() *EventStream {
+	r.Header().Set(HEADER_ContentType, MIME_EventStream)
+	r.Header().Set("Cache-Control", "no-cache")
+	r.Header().Set("X-Accel-Buffering", "no") // disable buffering by proxies such as nginx
+	r.Header().Del("Content-Length")
+	r.WriteHeader(http.StatusOK)
+	r.Flush()
+	ctx := context.Background()
+	if r.request != nil {
+		ctx = r.request.Context()
+	}
+	return &EventStream{response: r, ctx: ctx, stop: make(chan struct{})}
+}
+
+// LastEventID returns the value of the Last-Event-ID header, sent by a client reconnecting to an EventStream.
+func (r *Request) LastEventID() string {
+	return r.Request.Header.Get(HEADER_LastEventID)
+}
+
+// Done returns a channel that is closed when the client has gone away or the request is cancelled.
+func (s *EventStream) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Send writes the event and flushes the Response.
+func (s *EventStream) Send(event ServerSentEvent) error {
+	if strings.ContainsAny(event.ID, "\r\n") || strings.ContainsAny(event.Event, "\r\n") {
+		return errors.New("[restful] event id and type cannot contain newlines")
+	}
+	var frame bytes.Buffer
+	if event.ID != "" {
+		frame.WriteString("id: " + event.ID + "\n")
+	}
+	if event.Event != "" {
+		frame.WriteString("event: " + event.Event + "\n")
+	}
+	if event.Retry > 0 {
+		frame.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range splitEventLines(event.Data) {
+		frame.WriteString("data: " + line + "\n")
+	}
+	frame.WriteString("\n")
+	return s.write(frame.Bytes())
+}
+
+// Comment writes a comment line, which is ignored by clients but keeps the connection alive.
+func (s *EventStream) Comment(text string) error {
+	var frame bytes.Buffer
+	for _, line := range splitEventLines(text) {
+		frame.WriteString(": " + line + "\n")
+	}
+	frame.WriteString("\n")
+	return s.write(frame.Bytes())
+}
+
+// Heartbeat starts sending a keep-alive comment at each interval until the stream is closed or the client has gone away.
+// This prevents proxies from closing idle connections. Heartbeat does nothing if the interval is not positive.
+func (s *EventStream) Heartbeat(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if s.Comment("keep-alive") != nil {
+					return
+				}
+			case <-s.stop:
+				return
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the heartbeat ; further sends fail.
+func (s *EventStream) Close() {
+	s.protection.Lock()
+	defer s.protection.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.stop)
+}
+
+// write sends the frame unless the stream is closed or the client has gone away.
+func (s *EventStream) write(frame []byte) error {
+	s.protection.Lock()
+	defer s.protection.Unlock()
+	if s.closed {
+		return errEventStreamClosed
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.response.Write(frame); err != nil {
+		return err
+	}
+	s.response.Flush()
+	return nil
+}
+
+// splitEventLines splits the text on any of the line endings (CRLF, LF or CR) allowed by the event stream format.
+func splitEventLines(text string) []string {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	text = strings.Replace(text, "\r", "\n", -1)
+	return strings.Split(text, "\n")
+}
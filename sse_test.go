@@ -0,0 +1,94 @@
+package restful
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStreamSend(t *testing.T) {
+	httpWriter := httptest.NewRecorder()
+	stream := newResponse(httpWriter).StartEventStream()
+	stream.Send(ServerSentEvent{ID: "7", Event: "status", Data: "line1\nline2", Retry: 3 * time.Second})
+	stream.Send(ServerSentEvent{Data: "done"})
+	stream.Comment("keep-alive")
+	if err := stream.Send(ServerSentEvent{Event: "bad\nevent"}); err == nil {
+		t.Error("expected error for event type with newline")
+	}
+	stream.Close()
+	if err := stream.Send(ServerSentEvent{Data: "late"}); err != errEventStreamClosed {
+		t.Errorf("expected errEventStreamClosed, got %v", err)
+	}
+	expected := "id: 7\nevent: status\nretry: 3000\ndata: line1\ndata: line2\n\ndata: done\n\n: keep-alive\n\n"
+	if httpWriter.Body.String() != expected {
+		t.Errorf("unexpected frames:%q", httpWriter.Body.String())
+	}
+	if httpWriter.Header().Get(HEADER_ContentType) != MIME_EventStream || !httpWriter.Flushed {
+		t.Errorf("unexpected headers:%v", httpWriter.Header())
+	}
+}
+
+// go test -v -test.run TestEventStreamDisconnect ...restful
+func TestEventStreamDisconnect(t *testing.T) {
+	sent := make(chan error, 1)
+	container := NewContainer()
+	ws := new(WebService).Path("/jobs")
+	ws.Route(ws.GET("/{id}/events").To(func(req *Request, resp *Response) {
+		stream := resp.StartEventStream()
+		defer stream.Close()
+		stream.Heartbeat(10 * time.Millisecond)
+		stream.Send(ServerSentEvent{ID: req.LastEventID() + "1", Data: "running"})
+		<-stream.Done()
+		sent <- stream.Send(ServerSentEvent{Data: "gone"})
+	}))
+	container.Add(ws)
+	server := httptest.NewServer(container)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	httpRequest, _ := http.NewRequest("GET", server.URL+"/jobs/1/events", nil)
+	httpRequest.Header.Set(HEADER_LastEventID, "4")
+	httpResponse, err := http.DefaultClient.Do(httpRequest.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(httpResponse.Body)
+	for _, expected := range []string{"id: 41", "data: running", "", ": keep-alive"} {
+		if line, _ := reader.ReadString('\n'); strings.TrimSuffix(line, "\n") != expected {
+			t.Errorf("expected %q got %q", expected, line)
+		}
+	}
+	cancel()
+	select {
+	case err := <-sent:
+		if err == nil {
+			t.Error("expected Send to fail after the client went away")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("the disconnect was not noticed")
+	}
+}
+
+func TestEventStreamNoTimeout(t *testing.T) {
+	container := NewContainer()
+	container.Timeout(10 * time.Millisecond)
+	ws := new(WebService).Path("/jobs")
+	ws.Route(ws.GET("/{id}/events").NoTimeout().To(func(req *Request, resp *Response) {
+		stream := resp.StartEventStream()
+		defer stream.Close()
+		stream.Heartbeat(0) // ignored
+		time.Sleep(50 * time.Millisecond)
+		stream.Send(ServerSentEvent{Data: "done"})
+	}))
+	container.Add(ws)
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/jobs/1/events", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != http.StatusOK || httpWriter.Body.String() != "data: done\n\n" {
+		t.Errorf("unexpected response:%d %q", httpWriter.Code, httpWriter.Body.String())
+	}
+}
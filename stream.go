@@ -0,0 +1,108 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// errStreamClosed is returned when writing to a closed EntityStream.
+var errStreamClosed = errors.New("[restful] entity stream is closed")
+
+// EntityStream writes a sequence of entities as JSON, flushing the Response after each entity
+// such that the client can process them while the stream is produced.
+// Use Response.StreamNDJSON or Response.StreamJSONArray to create one.
+// The timeout of the Route (see Container.Timeout) still applies ; use RouteBuilder.NoTimeout for long-lived streams.
+//
+//	stream := resp.StreamNDJSON()
+//	for each := range events {
+//		if err := stream.Write(each); err != nil {
+//			return // the client went away
+//		}
+//	}
+//	stream.Close()
+type EntityStream struct {
+	response *Response
+	array    bool // otherwise newline-delimited
+	count    int
+	closed   bool
+}
+
+// StreamNDJSON returns an EntityStream that writes each entity as one line of JSON (application/x-ndjson).
+// A stream that may outlive the timeout of the Container should be produced by a Route built with NoTimeout.
+func (r *Response) StreamNDJSON() *EntityStream {
+	return r.newEntityStream(MIME_NDJSON, false)
+}
+
+// StreamJSONArray returns an EntityStream that writes the entities as elements of a JSON array (application/json).
+// Close must be called to end the array.
+func (r *Response) StreamJSONArray() *EntityStream {
+	return r.newEntityStream(MIME_JSON, true)
+}
+
+// newEntityStream sets the Content-Type ; the length of a stream is not known in advance.
+func (r *Response) newEntityStream(mimeType string, array bool) *EntityStream {
+	r.Header().Set(HEADER_ContentType, mimeType)
+	r.Header().Del("Content-Length")
+	return &EntityStream{response: r, array: array}
+}
+
+// Write encodes the value, writes it and flushes the Response.
+// It returns an error if the value cannot be encoded, the stream is closed, the request context is done
+// (e.g. the client went away or the request timed out) or writing fails.
+func (s *EntityStream) Write(value interface{}) error {
+	if s.closed {
+		return errStreamClosed
+	}
+	if s.response.request != nil {
+		if err := s.response.request.Context().Err(); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if s.array {
+		if s.count == 0 {
+			data = append([]byte("["), data...)
+		} else {
+			data = append([]byte(","), data...)
+		}
+	} else {
+		data = append(data, '\n')
+	}
+	if _, err := s.response.Write(data); err != nil {
+		return err
+	}
+	s.count++
+	s.response.Flush()
+	return nil
+}
+
+// Count returns the number of entities written.
+func (s *EntityStream) Count() int {
+	return s.count
+}
+
+// Close ends the stream ; for a JSON array the closing bracket is written (or [] if no entity was written).
+// Further writes fail.
+func (s *EntityStream) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if !s.array {
+		return nil
+	}
+	closing := "]"
+	if s.count == 0 {
+		closing = "[]"
+	}
+	_, err := s.response.Write([]byte(closing))
+	s.response.Flush()
+	return err
+}
@@ -0,0 +1,77 @@
+package restful
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// go test -v -test.run TestStreamNDJSON ...restful
+func TestStreamNDJSON(t *testing.T) {
+	container := NewContainer()
+	container.EnableContentEncoding(true)
+	container.Timeout(time.Minute)
+	ws := new(WebService).Path("/events")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		stream := resp.StreamNDJSON()
+		for _, each := range []string{"a", "b"} {
+			if err := stream.Write(map[string]string{"id": each}); err != nil {
+				t.Error(err)
+			}
+		}
+		stream.Close()
+		if err := stream.Write("c"); err != errStreamClosed {
+			t.Errorf("expected errStreamClosed, got %v", err)
+		}
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/events", nil)
+	httpRequest.Header.Set(HEADER_AcceptEncoding, ENCODING_GZIP)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if !httpWriter.Flushed {
+		t.Error("expected the response to be flushed through the compressor and timeout writer")
+	}
+	if httpWriter.Header().Get(HEADER_ContentType) != MIME_NDJSON {
+		t.Errorf("unexpected Content-Type:%s", httpWriter.Header().Get(HEADER_ContentType))
+	}
+	reader, err := gzip.NewReader(httpWriter.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(reader)
+	if string(body) != "{\"id\":\"a\"}\n{\"id\":\"b\"}\n" {
+		t.Errorf("unexpected body:%q", body)
+	}
+}
+
+func TestStreamJSONArray(t *testing.T) {
+	for count, expected := range map[int]string{0: "[]", 1: "[0]", 3: "[0,1,2]"} {
+		httpWriter := httptest.NewRecorder()
+		stream := newResponse(httpWriter).StreamJSONArray()
+		for i := 0; i < count; i++ {
+			stream.Write(i)
+		}
+		stream.Close()
+		if httpWriter.Body.String() != expected || stream.Count() != count {
+			t.Errorf("expected %s got %s", expected, httpWriter.Body.String())
+		}
+	}
+}
+
+func TestCompressingResponseWriterFlush(t *testing.T) {
+	httpWriter := httptest.NewRecorder()
+	compressing, _ := NewCompressingResponseWriter(httpWriter, ENCODING_DEFLATE)
+	compressing.Write([]byte("streamed"))
+	compressing.Flush()
+	if !httpWriter.Flushed || httpWriter.Body.Len() == 0 {
+		t.Error("expected compressed data to be flushed")
+	}
+	if _, _, err := compressing.Hijack(); err == nil {
+		t.Error("expected Hijack to fail for a writer that does not support it")
+	}
+}
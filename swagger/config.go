@@ -8,4 +8,9 @@ type Config struct {
 	SwaggerPath     string // [optional] path where the swagger UI will be served, e.g. /swagger
 	SwaggerFilePath string // [optional] location of folder containing Swagger HTML5 application index.html
 	WebServices     []*restful.WebService
+	OpenAPIPath     string                  // [optional] path where the OpenAPI 3 JSON document is available, e.g. /openapi.json
+	Swagger2Path    string                  // [optional] path where the Swagger 2.0 JSON document is available, e.g. /swagger.json
+	Info            Info                    // [optional] title, description and version of the API in the OpenAPI and Swagger 2.0 documents
+	Authenticators  []restful.Authenticator // [optional] authentication schemes documented as security definitions
+	OAuthFlows      map[string]OAuthFlow    // [optional] by scheme name, documents an Authenticator (e.g. of JWTs) as OAuth 2 such that Route Scopes are listed
 }
@@ -0,0 +1,168 @@
+package swagger
+
+const (
+	openAPIVersion  = "3.0.3"
+	swagger2Version = "2.0"
+)
+
+// OpenAPI is the root of an OpenAPI 3 document (https://spec.openapis.org/oas/v3.0.3)
+type OpenAPI struct {
+	OpenAPI    string              `json:"openapi"` // e.g. 3.0.3
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info provides metadata about the API ; it is shared by OpenAPI 3 and Swagger 2.0
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	Url string `json:"url"`
+}
+
+// PathItem maps a lowercase HTTP method (e.g. get) to the Operation on a path
+type PathItem map[string]*OpenAPIOperation
+
+type OpenAPIOperation struct {
+	Tags        []string                   `json:"tags,omitempty"`
+	Summary     string                     `json:"summary,omitempty"`
+	OperationId string                     `json:"operationId,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody               `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"` // key is the status code
+	Security    []SecurityRequirement      `json:"security,omitempty"`
+}
+
+type OpenAPIParameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path,query,header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Explode     *bool   `json:"explode,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"` // key is the MIME type
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type OpenAPIResponse struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"` // key is the MIME type
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes an authentication scheme in an OpenAPI 3 document
+type SecurityScheme struct {
+	Type   string `json:"type"`                   // http, apiKey or oauth2
+	Scheme string `json:"scheme,omitempty"`       // basic or bearer, only for http
+	Format string `json:"bearerFormat,omitempty"` // e.g. JWT, only for bearer
+	Name   string `json:"name,omitempty"`         // only for apiKey
+	In     string `json:"in,omitempty"`           // header or query, only for apiKey
+
+	Flows map[string]*OpenAPIOAuthFlow `json:"flows,omitempty"` // key is the flow, only for oauth2
+}
+
+// OpenAPIOAuthFlow describes how an OAuth 2 access token is obtained in an OpenAPI 3 document
+type OpenAPIOAuthFlow struct {
+	AuthorizationUrl string            `json:"authorizationUrl,omitempty"`
+	TokenUrl         string            `json:"tokenUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"` // description by scope
+}
+
+// OAuthFlow describes how a client obtains an OAuth 2 access token for a scheme, see Config.OAuthFlows
+type OAuthFlow struct {
+	Flow             string            // authorizationCode, clientCredentials, implicit or password
+	AuthorizationUrl string            // for authorizationCode and implicit
+	TokenUrl         string            // for authorizationCode, clientCredentials and password
+	Scopes           map[string]string // description by scope ; the Scopes of Routes that are missing are added without description
+}
+
+// SecurityRequirement maps the name of a security scheme to the required scopes ; it is shared by OpenAPI 3 and Swagger 2.0
+type SecurityRequirement map[string][]string
+
+// Schema is the subset of JSON Schema used by OpenAPI 3 and Swagger 2.0 to describe models and parameters
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`   // e.g. integer
+	Format               string             `json:"format,omitempty"` // e.g. int64
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"` // names of required properties
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Swagger2 is the root of a Swagger 2.0 document (http://swagger.io/specification/v2/)
+type Swagger2 struct {
+	Swagger     string                      `json:"swagger"` // 2.0
+	Info        Info                        `json:"info"`
+	Host        string                      `json:"host,omitempty"`
+	BasePath    string                      `json:"basePath,omitempty"`
+	Schemes     []string                    `json:"schemes,omitempty"`
+	Paths       map[string]Swagger2PathItem `json:"paths"`
+	Definitions map[string]*Schema          `json:"definitions,omitempty"`
+
+	SecurityDefinitions map[string]*Swagger2SecurityScheme `json:"securityDefinitions,omitempty"`
+}
+
+// Swagger2PathItem maps a lowercase HTTP method (e.g. get) to the Operation on a path
+type Swagger2PathItem map[string]*Swagger2Operation
+
+type Swagger2Operation struct {
+	Tags        []string                    `json:"tags,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	OperationId string                      `json:"operationId,omitempty"`
+	Consumes    []string                    `json:"consumes,omitempty"`
+	Produces    []string                    `json:"produces,omitempty"`
+	Parameters  []Swagger2Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]Swagger2Response `json:"responses"` // key is the status code
+	Security    []SecurityRequirement       `json:"security,omitempty"`
+}
+
+type Swagger2Parameter struct {
+	Name             string   `json:"name"`
+	In               string   `json:"in"` // path,query,header,body,formData
+	Description      string   `json:"description,omitempty"`
+	Required         bool     `json:"required,omitempty"`
+	Type             string   `json:"type,omitempty"` // not for body ; file for an uploaded file
+	Format           string   `json:"format,omitempty"`
+	Pattern          string   `json:"pattern,omitempty"`
+	Enum             []string `json:"enum,omitempty"`
+	Items            *Schema  `json:"items,omitempty"`
+	CollectionFormat string   `json:"collectionFormat,omitempty"` // e.g. csv
+	Schema           *Schema  `json:"schema,omitempty"`           // only for body
+}
+
+type Swagger2Response struct {
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// Swagger2SecurityScheme describes an authentication scheme in a Swagger 2.0 document
+type Swagger2SecurityScheme struct {
+	Type string `json:"type"`           // basic, apiKey or oauth2
+	Name string `json:"name,omitempty"` // only for apiKey
+	In   string `json:"in,omitempty"`   // header or query, only for apiKey
+
+	Flow             string            `json:"flow,omitempty"` // accessCode, application, implicit or password, only for oauth2
+	AuthorizationUrl string            `json:"authorizationUrl,omitempty"`
+	TokenUrl         string            `json:"tokenUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes,omitempty"` // description by scope, only for oauth2
+}
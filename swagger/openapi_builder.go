@@ -0,0 +1,441 @@
+package swagger
+
+import (
+	"github.com/squishyent/go-restful"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BuildOpenAPI returns the OpenAPI 3 document for all Routes of the WebServices in the config.
+// The WebServices that serve the documentation itself (ApiPath, OpenAPIPath and Swagger2Path) are skipped.
+func BuildOpenAPI(config Config) OpenAPI {
+	b := newOpenAPIBuilder("#/components/schemas/")
+	doc := OpenAPI{
+		OpenAPI:    openAPIVersion,
+		Info:       config.Info,
+		Paths:      map[string]PathItem{},
+		Components: Components{Schemas: b.schemas}}
+	if config.WebServicesUrl != "" {
+		doc.Servers = []Server{Server{Url: config.WebServicesUrl}}
+	}
+	for _, each := range config.Authenticators {
+		if scheme, ok := config.securityScheme(each); ok {
+			if doc.Components.SecuritySchemes == nil {
+				doc.Components.SecuritySchemes = map[string]*SecurityScheme{}
+			}
+			doc.Components.SecuritySchemes[each.SchemeName()] = scheme
+		}
+	}
+	for _, ws := range config.documentedWebServices() {
+		for _, route := range ws.Routes() {
+			path, patterns := asOpenAPIPath(route.Path)
+			item, ok := doc.Paths[path]
+			if !ok {
+				item = PathItem{}
+				doc.Paths[path] = item
+			}
+			operation := b.openAPIOperation(ws, route, patterns)
+			operation.Security = config.securityRequirements(route)
+			item[strings.ToLower(route.Method)] = operation
+		}
+	}
+	return doc
+}
+
+// BuildSwagger2 returns the Swagger 2.0 document for all Routes of the WebServices in the config.
+// The WebServices that serve the documentation itself (ApiPath, OpenAPIPath and Swagger2Path) are skipped.
+func BuildSwagger2(config Config) Swagger2 {
+	b := newOpenAPIBuilder("#/definitions/")
+	doc := Swagger2{
+		Swagger:     swagger2Version,
+		Info:        config.Info,
+		Paths:       map[string]Swagger2PathItem{},
+		Definitions: b.schemas}
+	if location, err := url.Parse(config.WebServicesUrl); err == nil && config.WebServicesUrl != "" {
+		doc.Host = location.Host
+		doc.BasePath = location.Path
+		if location.Scheme != "" {
+			doc.Schemes = []string{location.Scheme}
+		}
+	}
+	for _, each := range config.Authenticators {
+		if scheme, ok := config.swagger2SecurityScheme(each); ok {
+			if doc.SecurityDefinitions == nil {
+				doc.SecurityDefinitions = map[string]*Swagger2SecurityScheme{}
+			}
+			doc.SecurityDefinitions[each.SchemeName()] = scheme
+		}
+	}
+	for _, ws := range config.documentedWebServices() {
+		for _, route := range ws.Routes() {
+			path, patterns := asOpenAPIPath(route.Path)
+			item, ok := doc.Paths[path]
+			if !ok {
+				item = Swagger2PathItem{}
+				doc.Paths[path] = item
+			}
+			operation := b.swagger2Operation(ws, route, patterns)
+			operation.Security = config.securityRequirements(route)
+			item[strings.ToLower(route.Method)] = operation
+		}
+	}
+	return doc
+}
+
+// documentedWebServices returns the WebServices of the config except those that serve the documentation.
+func (c Config) documentedWebServices() []*restful.WebService {
+	services := []*restful.WebService{}
+	for _, each := range c.WebServices {
+		root := each.RootPath()
+		if root == c.ApiPath || (c.OpenAPIPath != "" && root == c.OpenAPIPath) || (c.Swagger2Path != "" && root == c.Swagger2Path) {
+			continue
+		}
+		services = append(services, each)
+	}
+	return services
+}
+
+// openAPIBuilder collects the schemas of all models while composing the operations.
+type openAPIBuilder struct {
+	schemas   map[string]*Schema
+	refPrefix string // location of the schemas in the document
+}
+
+func newOpenAPIBuilder(refPrefix string) *openAPIBuilder {
+	return &openAPIBuilder{schemas: map[string]*Schema{}, refPrefix: refPrefix}
+}
+
+func (b *openAPIBuilder) openAPIOperation(ws *restful.WebService, route restful.Route, patterns map[string]string) *OpenAPIOperation {
+	operation := &OpenAPIOperation{
+		Tags:        asTags(ws),
+		Summary:     route.Doc,
+		OperationId: route.Operation,
+		Responses:   map[string]OpenAPIResponse{}}
+	form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, param := range routeParameters(ws, route, patterns) {
+		data := param.Data()
+		if data.Kind == restful.BODY_PARAMETER {
+			operation.RequestBody = &RequestBody{
+				Description: data.Description,
+				Required:    data.Required,
+				Content:     b.contentOf(orJSON(route.Consumes), route.ReadSample, data.DataType)}
+			continue
+		}
+		if data.Kind == restful.FORM_PARAMETER {
+			form.Properties[data.Name] = parameterSchema(data, "")
+			if data.Required {
+				form.Required = append(form.Required, data.Name)
+			}
+			continue
+		}
+		openAPIParam := OpenAPIParameter{
+			Name:        data.Name,
+			In:          asParamType(data.Kind),
+			Description: data.Description,
+			Required:    data.Required || data.Kind == restful.PATH_PARAMETER,
+			Schema:      parameterSchema(data, patterns[data.Name])}
+		if data.AllowMultiple {
+			explode := false // comma separated
+			openAPIParam.Explode = &explode
+		}
+		operation.Parameters = append(operation.Parameters, openAPIParam)
+	}
+	if len(form.Properties) > 0 && operation.RequestBody == nil {
+		operation.RequestBody = &RequestBody{Required: len(form.Required) > 0, Content: map[string]MediaType{}}
+		for _, each := range formMimeTypes(route.Consumes) {
+			operation.RequestBody.Content[each] = MediaType{Schema: form}
+		}
+	}
+	response := OpenAPIResponse{Description: "OK"}
+	if route.WriteSample != nil {
+		response.Content = b.contentOf(orJSON(producesOf(route)), route.WriteSample, "")
+	}
+	operation.Responses["200"] = response
+	for _, code := range sortedResponseCodes(route) {
+		each := route.ResponseErrors[code]
+		response := OpenAPIResponse{Description: each.Message, Content: operation.Responses[strconv.Itoa(code)].Content}
+		if each.Model != nil {
+			response.Content = b.contentOf(orJSON(producesOf(route)), each.Model, "")
+		}
+		operation.Responses[strconv.Itoa(code)] = response
+	}
+	return operation
+}
+
+func (b *openAPIBuilder) swagger2Operation(ws *restful.WebService, route restful.Route, patterns map[string]string) *Swagger2Operation {
+	operation := &Swagger2Operation{
+		Tags:        asTags(ws),
+		Summary:     route.Doc,
+		OperationId: route.Operation,
+		Consumes:    route.Consumes,
+		Produces:    producesOf(route),
+		Responses:   map[string]Swagger2Response{}}
+	for _, param := range routeParameters(ws, route, patterns) {
+		data := param.Data()
+		swaggerParam := Swagger2Parameter{
+			Name:        data.Name,
+			In:          asParamType(data.Kind),
+			Description: data.Description,
+			Required:    data.Required || data.Kind == restful.PATH_PARAMETER}
+		if data.Kind == restful.BODY_PARAMETER {
+			swaggerParam.Schema = b.schemaOf(route.ReadSample, data.DataType)
+		} else if data.Kind == restful.FORM_PARAMETER && isFileDataType(data.DataType) {
+			swaggerParam.In, swaggerParam.Type = "formData", "file"
+		} else {
+			schema := parameterSchema(data, patterns[data.Name])
+			if schema.Type == "array" {
+				swaggerParam.CollectionFormat = "csv"
+				swaggerParam.Items = schema.Items
+			}
+			swaggerParam.Type, swaggerParam.Format = schema.Type, schema.Format
+			swaggerParam.Pattern, swaggerParam.Enum = schema.Pattern, schema.Enum
+			if data.Kind == restful.FORM_PARAMETER {
+				swaggerParam.In = "formData"
+			}
+		}
+		operation.Parameters = append(operation.Parameters, swaggerParam)
+	}
+	response := Swagger2Response{Description: "OK"}
+	if route.WriteSample != nil {
+		response.Schema = b.schemaOf(route.WriteSample, "")
+	}
+	operation.Responses["200"] = response
+	for _, code := range sortedResponseCodes(route) {
+		each := route.ResponseErrors[code]
+		response := Swagger2Response{Description: each.Message, Schema: operation.Responses[strconv.Itoa(code)].Schema}
+		if each.Model != nil {
+			response.Schema = b.schemaOf(each.Model, "")
+		}
+		operation.Responses[strconv.Itoa(code)] = response
+	}
+	return operation
+}
+
+// contentOf returns the same schema for each of the MIME types.
+func (b *openAPIBuilder) contentOf(mimeTypes []string, sample interface{}, dataType string) map[string]MediaType {
+	schema := b.schemaOf(sample, dataType)
+	content := map[string]MediaType{}
+	for _, each := range mimeTypes {
+		content[each] = MediaType{Schema: schema}
+	}
+	return content
+}
+
+// schemaOf returns the schema of the sample or, if nil, of the data type.
+func (b *openAPIBuilder) schemaOf(sample interface{}, dataType string) *Schema {
+	if sample != nil {
+		return b.schemaOfType(reflect.TypeOf(sample))
+	}
+	return schemaOfDataType(dataType)
+}
+
+// schemaOfType returns the schema for a Go type ; a named struct is added to the schemas and referenced.
+func (b *openAPIBuilder) schemaOfType(st reflect.Type) *Schema {
+	for st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st == reflect.TypeOf(time.Time{}) {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	switch st.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if st.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schemaOfType(st.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOfType(st.Elem())}
+	case reflect.Struct:
+		if st.Name() == "" {
+			return b.structSchema(st)
+		}
+		modelName := st.String()
+		// see if we already have visited this model ; store before further initializing
+		if _, ok := b.schemas[modelName]; !ok {
+			b.schemas[modelName] = &Schema{Type: "object"}
+			b.schemas[modelName] = b.structSchema(st)
+		}
+		return &Schema{Ref: b.refPrefix + modelName}
+	}
+	return &Schema{}
+}
+
+// structSchema returns an object schema with a property for each field that is encoded by encoding/json.
+func (b *openAPIBuilder) structSchema(st reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue // unexported
+		}
+		jsonName := sf.Name
+		if override := strings.Split(sf.Tag.Get("json"), ",")[0]; override == "-" {
+			continue
+		} else if override != "" {
+			jsonName = override
+		} else if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			// fields of an embedded struct are promoted
+			for name, each := range b.structSchema(sf.Type).Properties {
+				schema.Properties[name] = each
+			}
+			continue
+		}
+		schema.Properties[jsonName] = b.schemaOfType(sf.Type)
+	}
+	return schema
+}
+
+// schemaOfDataType returns the schema for the DataType of a Parameter ; unknown types are described as string.
+func schemaOfDataType(dataType string) *Schema {
+	switch strings.ToLower(dataType) {
+	case "integer", "int", "int64", "long":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "int32":
+		return &Schema{Type: "integer", Format: "int32"}
+	case "number", "double", "float64":
+		return &Schema{Type: "number", Format: "double"}
+	case "float", "float32":
+		return &Schema{Type: "number", Format: "float"}
+	case "boolean", "bool":
+		return &Schema{Type: "boolean"}
+	case "date":
+		return &Schema{Type: "string", Format: "date"}
+	case "date-time", "datetime", "time":
+		return &Schema{Type: "string", Format: "date-time"}
+	case "file":
+		return &Schema{Type: "string", Format: "binary"}
+	}
+	return &Schema{Type: "string"}
+}
+
+// isFileDataType returns whether the DataType of a form Parameter denotes an uploaded file.
+func isFileDataType(dataType string) bool {
+	return strings.ToLower(dataType) == "file"
+}
+
+// formMimeTypes returns the form MIME types of the Consumes ; multipart/form-data if there are none.
+func formMimeTypes(consumes []string) []string {
+	forms := []string{}
+	for _, each := range consumes {
+		if each == restful.MIME_MultipartForm || each == restful.MIME_URLEncodedForm {
+			forms = append(forms, each)
+		}
+	}
+	if len(forms) == 0 {
+		return []string{restful.MIME_MultipartForm}
+	}
+	return forms
+}
+
+// parameterSchema returns the schema of a path, query, header or form Parameter.
+func parameterSchema(param restful.ParameterData, pattern string) *Schema {
+	schema := schemaOfDataType(param.DataType)
+	schema.Pattern = pattern
+	for each := range param.AllowableValues {
+		schema.Enum = append(schema.Enum, each)
+	}
+	sort.Strings(schema.Enum)
+	if param.AllowMultiple {
+		return &Schema{Type: "array", Items: schema}
+	}
+	return schema
+}
+
+// routeParameters returns the Parameters of the WebService root path and of the Route.
+// Path parameters that are not documented are added as a string.
+func routeParameters(ws *restful.WebService, route restful.Route, patterns map[string]string) []*restful.Parameter {
+	params := append([]*restful.Parameter{}, ws.PathParameters()...)
+	params = append(params, route.ParameterDocs...)
+	documented := map[string]bool{}
+	for _, each := range params {
+		if each.Kind() == restful.PATH_PARAMETER {
+			documented[each.Data().Name] = true
+		}
+	}
+	names := []string{}
+	for name := range patterns {
+		if !documented[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		params = append(params, ws.PathParameter(name, ""))
+	}
+	return params
+}
+
+// asOpenAPIPath removes the constraints from the path parameters of a Route path, e.g. /{id:[0-9]+} becomes /{id}.
+// It returns the path and the regular expression of each path parameter (empty if unconstrained or catch-all).
+func asOpenAPIPath(routePath string) (string, map[string]string) {
+	patterns := map[string]string{}
+	tokens := strings.Split(strings.Trim(routePath, "/"), "/")
+	for i, each := range tokens {
+		if !strings.HasPrefix(each, "{") || !strings.HasSuffix(each, "}") {
+			continue
+		}
+		name, pattern := each[1:len(each)-1], ""
+		if colon := strings.Index(name, ":"); colon != -1 {
+			name, pattern = strings.TrimSpace(name[:colon]), strings.TrimSpace(name[colon+1:])
+		}
+		if pattern == "*" {
+			pattern = ""
+		} else if pattern != "" {
+			pattern = "^" + pattern + "$"
+		}
+		patterns[name] = pattern
+		tokens[i] = "{" + name + "}"
+	}
+	return "/" + strings.Join(tokens, "/"), patterns
+}
+
+// asTags returns the root path of the WebService (without slashes) as the single tag.
+func asTags(ws *restful.WebService) []string {
+	if tag := strings.Trim(ws.RootPath(), "/"); tag != "" {
+		return []string{tag}
+	}
+	return nil
+}
+
+// orJSON returns the MIME types or, if empty, only restful.MIME_JSON
+// producesOf returns the Produces of the Route without their q parameters (e.g. "application/xml;q=0.5").
+func producesOf(route restful.Route) []string {
+	if len(route.Produces) == 0 {
+		return route.Produces
+	}
+	produces := []string{}
+	for _, each := range route.Produces {
+		parts := strings.Split(each, ";")
+		kept := parts[:1]
+		for _, param := range parts[1:] {
+			if name := strings.ToLower(strings.TrimSpace(param)); !strings.HasPrefix(name, "q=") {
+				kept = append(kept, param)
+			}
+		}
+		produces = append(produces, strings.TrimSpace(strings.Join(kept, ";")))
+	}
+	return produces
+}
+
+func orJSON(mimeTypes []string) []string {
+	if len(mimeTypes) == 0 {
+		return []string{restful.MIME_JSON}
+	}
+	return mimeTypes
+}
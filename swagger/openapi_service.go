@@ -0,0 +1,42 @@
+package swagger
+
+import (
+	"github.com/squishyent/go-restful"
+)
+
+// RegisterOpenAPIService adds the WebServices that provide the API documentation of all services
+// as a single OpenAPI 3 document at config.OpenAPIPath and, if set, as a Swagger 2.0 document at config.Swagger2Path.
+// If config.WebServices is empty then all WebServices registered in the container are documented.
+// The documents are composed for each request such that WebServices added later are included.
+func RegisterOpenAPIService(config Config, wsContainer *restful.Container) {
+	if config.OpenAPIPath != "" {
+		ws := new(restful.WebService)
+		ws.Path(config.OpenAPIPath)
+		ws.Produces(restful.MIME_JSON)
+		ws.Filter(enableCORS)
+		ws.Route(ws.GET("").To(func(req *restful.Request, resp *restful.Response) {
+			resp.WriteAsJson(BuildOpenAPI(configFor(config, wsContainer)))
+		}))
+		LogInfo("[restful/swagger] OpenAPI 3 document is available at %v%v", config.WebServicesUrl, config.OpenAPIPath)
+		wsContainer.Add(ws)
+	}
+	if config.Swagger2Path != "" {
+		ws := new(restful.WebService)
+		ws.Path(config.Swagger2Path)
+		ws.Produces(restful.MIME_JSON)
+		ws.Filter(enableCORS)
+		ws.Route(ws.GET("").To(func(req *restful.Request, resp *restful.Response) {
+			resp.WriteAsJson(BuildSwagger2(configFor(config, wsContainer)))
+		}))
+		LogInfo("[restful/swagger] Swagger 2.0 document is available at %v%v", config.WebServicesUrl, config.Swagger2Path)
+		wsContainer.Add(ws)
+	}
+}
+
+// configFor returns the config with the WebServices of the container if none are configured.
+func configFor(config Config, wsContainer *restful.Container) Config {
+	if len(config.WebServices) == 0 {
+		config.WebServices = wsContainer.RegisteredWebServices()
+	}
+	return config
+}
@@ -0,0 +1,295 @@
+package swagger
+
+import (
+	"encoding/json"
+	"github.com/squishyent/go-restful"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type order struct {
+	Id      int64 `json:"id"`
+	Lines   []orderLine
+	Created time.Time `json:"created"`
+	Secret  string    `json:"-"`
+	Parent  *order    `json:"parent,omitempty"`
+	Labels  map[string]string
+}
+
+type orderLine struct {
+	Quantity float32
+}
+
+func newOrderService() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path("/orders").Consumes(restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_XML+";q=0.5")
+	ws.Route(ws.GET("/{id:[0-9]+}").To(dummy).
+		Doc("get an order").
+		Operation("getOrder").
+		Param(ws.PathParameter("id", "identifier").DataType("integer")).
+		Param(ws.QueryParameter("fields", "fields to include").AllowMultiple(true)).
+		Param(ws.QueryParameter("sort", "").AllowableValues(map[string]string{"desc": "", "asc": ""})).
+		Writes(order{}))
+	ws.Route(ws.POST("").To(dummy).Operation("createOrder").Reads(order{}))
+	ws.Route(ws.GET("/{id}/files/{path:*}").To(dummy))
+	return ws
+}
+
+// go test -v -test.run TestBuildOpenAPI ...swagger
+func TestBuildOpenAPI(t *testing.T) {
+	doc := BuildOpenAPI(Config{
+		WebServicesUrl: "http://here.com/api",
+		Info:           Info{Title: "orders", Version: "1.0"},
+		WebServices:    []*restful.WebService{newOrderService()}})
+	if doc.OpenAPI != "3.0.3" || doc.Servers[0].Url != "http://here.com/api" {
+		t.Errorf("unexpected header:%#v", doc)
+	}
+	get := doc.Paths["/orders/{id}"]["get"]
+	if get == nil || get.OperationId != "getOrder" || get.Tags[0] != "orders" {
+		t.Fatalf("missing get operation:%#v", doc.Paths)
+	}
+	if len(get.Parameters) != 3 {
+		t.Fatalf("unexpected parameters:%#v", get.Parameters)
+	}
+	if _, ok := get.Responses["200"].Content[restful.MIME_XML]; !ok {
+		t.Errorf("expected the Produces without quality:%#v", get.Responses["200"].Content)
+	}
+	id, fields, sort := get.Parameters[0], get.Parameters[1], get.Parameters[2]
+	if id.In != "path" || !id.Required || id.Schema.Type != "integer" || id.Schema.Pattern != "^[0-9]+$" {
+		t.Errorf("unexpected id:%#v %#v", id, id.Schema)
+	}
+	if fields.Schema.Type != "array" || fields.Schema.Items.Type != "string" || *fields.Explode {
+		t.Errorf("unexpected fields:%#v", fields.Schema)
+	}
+	if len(sort.Schema.Enum) != 2 || sort.Schema.Enum[0] != "asc" {
+		t.Errorf("unexpected sort:%#v", sort.Schema)
+	}
+	if ref := get.Responses["200"].Content[restful.MIME_XML].Schema.Ref; ref != "#/components/schemas/swagger.order" {
+		t.Errorf("unexpected response ref:%s", ref)
+	}
+	post := doc.Paths["/orders"]["post"]
+	if post == nil || post.RequestBody == nil || post.RequestBody.Content[restful.MIME_JSON].Schema.Ref != "#/components/schemas/swagger.order" {
+		t.Fatalf("unexpected post:%#v", post)
+	}
+	files := doc.Paths["/orders/{id}/files/{path}"]["get"]
+	if files == nil || len(files.Parameters) != 2 || files.Parameters[1].Name != "path" || files.Parameters[1].Schema.Pattern != "" {
+		t.Fatalf("unexpected catch-all operation:%#v", files)
+	}
+
+	model := doc.Components.Schemas["swagger.order"]
+	if model == nil {
+		t.Fatal("missing order schema")
+	}
+	if _, ok := model.Properties["Secret"]; ok {
+		t.Error("json:- field should be skipped")
+	}
+	if model.Properties["created"].Format != "date-time" || model.Properties["parent"].Ref != "#/components/schemas/swagger.order" {
+		t.Errorf("unexpected properties:%#v", model.Properties)
+	}
+	if lines := model.Properties["Lines"]; lines.Type != "array" || lines.Items.Ref != "#/components/schemas/swagger.orderLine" {
+		t.Errorf("unexpected lines:%#v", lines)
+	}
+	if labels := model.Properties["Labels"]; labels.Type != "object" || labels.AdditionalProperties.Type != "string" {
+		t.Errorf("unexpected labels:%#v", labels)
+	}
+	if quantity := doc.Components.Schemas["swagger.orderLine"].Properties["Quantity"]; quantity.Format != "float" {
+		t.Errorf("unexpected quantity:%#v", quantity)
+	}
+}
+
+// go test -v -test.run TestBuildSwagger2 ...swagger
+func TestBuildSwagger2(t *testing.T) {
+	doc := BuildSwagger2(Config{
+		WebServicesUrl: "https://here.com/api",
+		WebServices:    []*restful.WebService{newOrderService()}})
+	if doc.Swagger != "2.0" || doc.Host != "here.com" || doc.BasePath != "/api" || doc.Schemes[0] != "https" {
+		t.Errorf("unexpected header:%#v", doc)
+	}
+	get := doc.Paths["/orders/{id}"]["get"]
+	if get == nil || get.Produces[1] != restful.MIME_XML {
+		t.Fatalf("missing get operation:%#v", doc.Paths)
+	}
+	if fields := get.Parameters[1]; fields.Type != "array" || fields.CollectionFormat != "csv" || fields.Items.Type != "string" {
+		t.Errorf("unexpected fields:%#v", fields)
+	}
+	if ref := get.Responses["200"].Schema.Ref; ref != "#/definitions/swagger.order" {
+		t.Errorf("unexpected response ref:%s", ref)
+	}
+	body := doc.Paths["/orders"]["post"].Parameters[0]
+	if body.In != "body" || body.Schema.Ref != "#/definitions/swagger.order" {
+		t.Errorf("unexpected body:%#v", body)
+	}
+	if _, ok := doc.Definitions["swagger.orderLine"]; !ok {
+		t.Error("missing orderLine definition")
+	}
+}
+
+// go test -v -test.run TestRegisterOpenAPIService ...swagger
+func TestRegisterOpenAPIService(t *testing.T) {
+	container := restful.NewContainer()
+	container.Add(newOrderService())
+	RegisterOpenAPIService(Config{OpenAPIPath: "/openapi.json", Swagger2Path: "/swagger.json"}, container)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/openapi.json", nil)
+	httpWriter := httptest.NewRecorder()
+	container.ServeHTTP(httpWriter, httpRequest)
+	if httpWriter.Code != http.StatusOK {
+		t.Fatalf("unexpected status:%d", httpWriter.Code)
+	}
+	doc := OpenAPI{}
+	if err := json.Unmarshal(httpWriter.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Paths) != 3 {
+		t.Errorf("documentation services should be skipped:%v", doc.Paths)
+	}
+
+	httpRequest, _ = http.NewRequest("GET", "http://here.com/swagger.json", nil)
+	httpWriter = httptest.NewRecorder()
+	container.ServeHTTP(httpWriter, httpRequest)
+	swagger2 := Swagger2{}
+	if err := json.Unmarshal(httpWriter.Body.Bytes(), &swagger2); err != nil || swagger2.Swagger != "2.0" {
+		t.Fatalf("unexpected swagger 2.0 document:%v %s", err, httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestBuildOpenAPIResponses ...swagger
+func TestBuildOpenAPIResponses(t *testing.T) {
+	ws := new(restful.WebService)
+	ws.Path("/users").Produces(restful.MIME_JSON)
+	ws.Route(ws.PUT("/{id}").To(dummy).
+		Writes(User{}).
+		Returns(200, "updated", nil).
+		Returns(404, "user not found", nil).
+		Returns(409, "conflict", restful.ServiceError{}))
+	config := Config{WebServices: []*restful.WebService{ws}}
+
+	doc := BuildOpenAPI(config)
+	responses := doc.Paths["/users/{id}"]["put"].Responses
+	if ok := responses["200"]; ok.Description != "updated" || ok.Content[restful.MIME_JSON].Schema.Ref != "#/components/schemas/swagger.User" {
+		t.Errorf("unexpected 200:%#v", ok)
+	}
+	if notFound := responses["404"]; notFound.Description != "user not found" || notFound.Content != nil {
+		t.Errorf("unexpected 404:%#v", notFound)
+	}
+	if conflict := responses["409"]; conflict.Content[restful.MIME_JSON].Schema.Ref != "#/components/schemas/restful.ServiceError" {
+		t.Errorf("unexpected 409:%#v", conflict)
+	}
+	if _, ok := doc.Components.Schemas["restful.ServiceError"]; !ok {
+		t.Error("missing ServiceError schema")
+	}
+
+	swagger2 := BuildSwagger2(config)
+	if conflict := swagger2.Paths["/users/{id}"]["put"].Responses["409"]; conflict.Schema.Ref != "#/definitions/restful.ServiceError" {
+		t.Errorf("unexpected 409:%#v", conflict)
+	}
+}
+
+func TestSecuritySchemes(t *testing.T) {
+	check := func(token string) (interface{}, error) { return token, nil }
+	ws := new(restful.WebService).Path("/orders")
+	ws.Route(ws.GET("").Security("bearer", "apiKey").Scopes("orders:read").To(dummy))
+	ws.Route(ws.POST("").To(dummy))
+	config := Config{
+		WebServices: []*restful.WebService{ws},
+		Authenticators: []restful.Authenticator{
+			restful.NewBearerAuthenticator("orders", check),
+			restful.NewAPIKeyAuthenticator("key", restful.QUERY_PARAMETER, check)}}
+
+	openAPI := BuildOpenAPI(config)
+	if bearer := openAPI.Components.SecuritySchemes["bearer"]; bearer == nil || bearer.Type != "http" || bearer.Scheme != "bearer" {
+		t.Errorf("unexpected bearer scheme:%#v", bearer)
+	}
+	if apiKey := openAPI.Components.SecuritySchemes["apiKey"]; apiKey == nil || apiKey.Name != "key" || apiKey.In != "query" {
+		t.Errorf("unexpected apiKey scheme:%#v", apiKey)
+	}
+	security := openAPI.Paths["/orders"]["get"].Security
+	if len(security) != 2 || security[0]["bearer"] == nil || len(security[0]["bearer"]) != 0 || security[1]["apiKey"] == nil || len(security[1]["apiKey"]) != 0 {
+		t.Errorf("unexpected security:%#v", security)
+	}
+	if jwt, ok := asSecurityScheme(restful.NewJWTAuthenticator("orders", nil)); !ok || jwt.Format != "JWT" {
+		t.Errorf("unexpected jwt scheme:%#v", jwt)
+	}
+	if len(openAPI.Paths["/orders"]["post"].Security) != 0 {
+		t.Error("undeclared Route should have no security")
+	}
+
+	swagger2 := BuildSwagger2(config)
+	if bearer := swagger2.SecurityDefinitions["bearer"]; bearer == nil || bearer.Type != "apiKey" || bearer.Name != "Authorization" {
+		t.Errorf("unexpected bearer definition:%#v", bearer)
+	}
+	if security := swagger2.Paths["/orders"]["get"].Security; len(security) != 2 || len(security[0]["bearer"]) != 0 {
+		t.Errorf("unexpected security:%#v", swagger2.Paths["/orders"]["get"].Security)
+	}
+
+	if authorization, ok := asAuthorization(config.Authenticators[1]); !ok || authorization.PassAs != "query" || authorization.Keyname != "key" {
+		t.Errorf("unexpected authorization:%#v", authorization)
+	}
+	data, _ := json.Marshal(SecurityRequirement{"bearer": []string{}})
+	if string(data) != `{"bearer":[]}` {
+		t.Errorf("unexpected requirement:%s", data)
+	}
+}
+
+func TestOAuthSecurityScheme(t *testing.T) {
+	ws := new(restful.WebService).Path("/orders")
+	ws.Route(ws.GET("").Security("bearer").Scopes("orders:read").To(dummy))
+	config := Config{
+		WebServices:    []*restful.WebService{ws},
+		Authenticators: []restful.Authenticator{restful.NewJWTAuthenticator("orders", nil)},
+		OAuthFlows: map[string]OAuthFlow{"bearer": OAuthFlow{
+			Flow:     "clientCredentials",
+			TokenUrl: "https://idp.example.com/token",
+			Scopes:   map[string]string{"orders:write": "change orders"}}}}
+
+	openAPI := BuildOpenAPI(config)
+	bearer := openAPI.Components.SecuritySchemes["bearer"]
+	if bearer == nil || bearer.Type != "oauth2" || bearer.Flows["clientCredentials"] == nil {
+		t.Fatalf("unexpected bearer scheme:%#v", bearer)
+	}
+	if scopes := bearer.Flows["clientCredentials"].Scopes; len(scopes) != 2 || scopes["orders:write"] != "change orders" {
+		t.Errorf("unexpected scopes:%v", scopes)
+	}
+	if security := openAPI.Paths["/orders"]["get"].Security; len(security) != 1 || len(security[0]["bearer"]) != 1 || security[0]["bearer"][0] != "orders:read" {
+		t.Errorf("unexpected security:%#v", security)
+	}
+
+	swagger2 := BuildSwagger2(config)
+	if definition := swagger2.SecurityDefinitions["bearer"]; definition == nil || definition.Type != "oauth2" || definition.Flow != "application" || definition.TokenUrl == "" {
+		t.Errorf("unexpected bearer definition:%#v", definition)
+	}
+	if security := swagger2.Paths["/orders"]["get"].Security; len(security) != 1 || len(security[0]["bearer"]) != 1 || security[0]["bearer"][0] != "orders:read" {
+		t.Errorf("unexpected security:%#v", security)
+	}
+}
+
+func TestFormParameters(t *testing.T) {
+	ws := new(restful.WebService).Path("/images")
+	ws.Route(ws.POST("").Consumes(restful.MIME_MultipartForm).
+		Param(ws.FormParameter("title", "title of the image")).
+		Param(ws.FormParameter("image", "the image").DataType("file").Required(true)).
+		To(dummy))
+	config := Config{WebServices: []*restful.WebService{ws}}
+
+	body := BuildOpenAPI(config).Paths["/images"]["post"].RequestBody
+	if body == nil || !body.Required {
+		t.Fatalf("expected required request body, got %#v", body)
+	}
+	form := body.Content[restful.MIME_MultipartForm].Schema
+	if form == nil || form.Type != "object" || form.Properties["image"].Format != "binary" || form.Properties["title"].Type != "string" {
+		t.Errorf("unexpected form schema:%#v", form)
+	}
+	if len(form.Required) != 1 || form.Required[0] != "image" {
+		t.Errorf("unexpected required properties:%v", form.Required)
+	}
+
+	params := BuildSwagger2(config).Paths["/images"]["post"].Parameters
+	if len(params) != 2 || params[0].In != "formData" || params[0].Type != "string" || params[1].In != "formData" || params[1].Type != "file" {
+		t.Errorf("unexpected form parameters:%#v", params)
+	}
+	if asParamType(restful.FORM_PARAMETER) != "form" {
+		t.Error("expected form param type")
+	}
+}
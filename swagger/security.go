@@ -0,0 +1,148 @@
+package swagger
+
+import "github.com/squishyent/go-restful"
+
+// asSecurityScheme returns the OpenAPI 3 description of a built-in Authenticator ; false if it is not known.
+func asSecurityScheme(authenticator restful.Authenticator) (*SecurityScheme, bool) {
+	switch each := authenticator.(type) {
+	case *restful.BasicAuthenticator:
+		return &SecurityScheme{Type: "http", Scheme: "basic"}, true
+	case *restful.BearerAuthenticator:
+		return &SecurityScheme{Type: "http", Scheme: "bearer"}, true
+	case *restful.JWTAuthenticator:
+		return &SecurityScheme{Type: "http", Scheme: "bearer", Format: "JWT"}, true
+	case *restful.APIKeyAuthenticator:
+		return &SecurityScheme{Type: "apiKey", Name: each.KeyName, In: asParamType(each.In)}, true
+	}
+	return nil, false
+}
+
+// swagger2OAuthFlows maps the names of OAuth 2 flows in OpenAPI 3 to those in Swagger 2.0.
+var swagger2OAuthFlows = map[string]string{
+	"authorizationCode": "accessCode",
+	"clientCredentials": "application",
+	"implicit":          "implicit",
+	"password":          "password"}
+
+// securityScheme returns the OpenAPI 3 description of the Authenticator ; an oauth2 scheme if it has an OAuthFlow.
+func (c Config) securityScheme(authenticator restful.Authenticator) (*SecurityScheme, bool) {
+	flow, ok := c.OAuthFlows[authenticator.SchemeName()]
+	if !ok {
+		return asSecurityScheme(authenticator)
+	}
+	return &SecurityScheme{Type: "oauth2", Flows: map[string]*OpenAPIOAuthFlow{flow.Flow: &OpenAPIOAuthFlow{
+		AuthorizationUrl: flow.AuthorizationUrl,
+		TokenUrl:         flow.TokenUrl,
+		Scopes:           c.oauthScopes(authenticator.SchemeName(), flow)}}}, true
+}
+
+// swagger2SecurityScheme returns the Swagger 2.0 description of the Authenticator ; an oauth2 scheme if it has an OAuthFlow.
+func (c Config) swagger2SecurityScheme(authenticator restful.Authenticator) (*Swagger2SecurityScheme, bool) {
+	flow, ok := c.OAuthFlows[authenticator.SchemeName()]
+	if !ok {
+		return asSwagger2SecurityScheme(authenticator)
+	}
+	return &Swagger2SecurityScheme{Type: "oauth2",
+		Flow:             swagger2OAuthFlows[flow.Flow],
+		AuthorizationUrl: flow.AuthorizationUrl,
+		TokenUrl:         flow.TokenUrl,
+		Scopes:           c.oauthScopes(authenticator.SchemeName(), flow)}, true
+}
+
+// oauthScopes returns the descriptions of the scopes of the flow and the Scopes of all Routes that declare the scheme.
+func (c Config) oauthScopes(name string, flow OAuthFlow) map[string]string {
+	scopes := map[string]string{}
+	for _, ws := range c.documentedWebServices() {
+		for _, route := range ws.Routes() {
+			for _, each := range route.Security {
+				if each != name {
+					continue
+				}
+				for _, scope := range route.Scopes {
+					scopes[scope] = ""
+				}
+			}
+		}
+	}
+	for scope, description := range flow.Scopes {
+		scopes[scope] = description
+	}
+	return scopes
+}
+
+// asSwagger2SecurityScheme returns the Swagger 2.0 description of a built-in Authenticator ; false if it is not known.
+// Swagger 2.0 has no bearer scheme so it is described as an API key in the Authorization header.
+func asSwagger2SecurityScheme(authenticator restful.Authenticator) (*Swagger2SecurityScheme, bool) {
+	switch each := authenticator.(type) {
+	case *restful.BasicAuthenticator:
+		return &Swagger2SecurityScheme{Type: "basic"}, true
+	case *restful.BearerAuthenticator, *restful.JWTAuthenticator:
+		return &Swagger2SecurityScheme{Type: "apiKey", Name: "Authorization", In: "header"}, true
+	case *restful.APIKeyAuthenticator:
+		return &Swagger2SecurityScheme{Type: "apiKey", Name: each.KeyName, In: asParamType(each.In)}, true
+	}
+	return nil, false
+}
+
+// asAuthorization returns the Swagger 1.2 description of a built-in Authenticator ; false if it is not known.
+func asAuthorization(authenticator restful.Authenticator) (ApiKey, bool) {
+	switch each := authenticator.(type) {
+	case *restful.BasicAuthenticator:
+		return ApiKey{Type: "basicAuth"}, true
+	case *restful.BearerAuthenticator, *restful.JWTAuthenticator:
+		return ApiKey{Type: "apiKey", PassAs: "header", Keyname: "Authorization"}, true
+	case *restful.APIKeyAuthenticator:
+		return ApiKey{Type: "apiKey", PassAs: asParamType(each.In), Keyname: each.KeyName}, true
+	}
+	return ApiKey{}, false
+}
+
+// securityRequirements returns one requirement for each scheme declared by the Route ; any of them is sufficient.
+// The Scopes of the Route are only listed for OAuth schemes ; other schemes require an empty list.
+func (c Config) securityRequirements(route restful.Route) []SecurityRequirement {
+	requirements := []SecurityRequirement{}
+	for _, each := range route.Security {
+		scopes := []string{}
+		if c.isOAuthScheme(each) && route.Scopes != nil {
+			scopes = route.Scopes
+		}
+		requirements = append(requirements, SecurityRequirement{each: scopes})
+	}
+	return requirements
+}
+
+// isOAuthScheme returns whether the Authenticator with the scheme name is documented as oauth2, see Config.OAuthFlows.
+func (c Config) isOAuthScheme(name string) bool {
+	for _, each := range c.Authenticators {
+		if each.SchemeName() != name {
+			continue
+		}
+		if scheme, ok := c.securityScheme(each); ok {
+			return scheme.Type == "oauth2"
+		}
+	}
+	return false
+}
+
+// operationAuthorizations returns the Swagger 1.2 authorizations of the Route: the Scopes (as OAuth)
+// and the first API key scheme declared by the Route. It returns nil if there are none.
+func (c Config) operationAuthorizations(route restful.Route) []Authorization {
+	authorization, found := Authorization{}, false
+	if len(route.Scopes) > 0 {
+		authorization.LocalOAuth, found = OAuth{Type: "oauth2", Scopes: route.Scopes}, true
+	}
+	for _, name := range route.Security {
+		for _, each := range c.Authenticators {
+			if each.SchemeName() != name {
+				continue
+			}
+			if apiKey, ok := asAuthorization(each); ok && apiKey.Type == "apiKey" && authorization.ApiKey.Type == "" {
+				authorization.ApiKey, found = apiKey, true
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return []Authorization{authorization}
+}
@@ -7,7 +7,8 @@ type ResourceListing struct {
 	ApiVersion     string `json:"apiVersion"`
 	SwaggerVersion string `json:"swaggerVersion"` // e.g 1.2
 	// BasePath       string `json:"basePath"`  obsolete in 1.1
-	Apis []ApiRef `json:"apis"`
+	Apis           []ApiRef          `json:"apis"`
+	Authorizations map[string]ApiKey `json:"authorizations,omitempty"` // key is the name of the scheme
 }
 
 type ApiRef struct {
@@ -117,6 +118,7 @@ type Endpoint struct {
 
 // https://github.com/wordnik/swagger-core/wiki/authorizations
 type ApiKey struct {
-	Type   string `json:"type"`   // e.g. apiKey
-	PassAs string `json:"passAs"` // e.g. header
+	Type    string `json:"type"`              // e.g. apiKey or basicAuth
+	PassAs  string `json:"passAs,omitempty"`  // e.g. header ; not for basicAuth
+	Keyname string `json:"keyname,omitempty"` // e.g. api_key ; not for basicAuth
 }
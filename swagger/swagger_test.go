@@ -120,3 +120,71 @@ func TestIssue78(t *testing.T) {
 	output, _ := json.MarshalIndent(decl, " ", " ")
 	os.Stdout.Write(output)
 }
+
+// go test -v -test.run TestResponseMessages ...swagger
+func TestResponseMessages(t *testing.T) {
+	ws := new(restful.WebService)
+	ws.Path("/users")
+	ws.Route(ws.GET("/{id}").To(dummy).
+		Writes(User{}).
+		Returns(409, "duplicate user", restful.ServiceError{}).
+		Returns(404, "user not found", nil).
+		Returns(422, "invalid users", []Item{}).
+		Returns(423, "locked users", &[]User{}))
+	sws := newSwaggerService(Config{WebServices: []*restful.WebService{ws}})
+	decl := sws.composeDeclaration("/users")
+	messages := decl.Apis[0].Operations[0].ResponseMessages
+	if len(messages) != 4 {
+		t.Fatalf("unexpected messages:%v", messages)
+	}
+	if messages[0].Code != 404 || messages[0].Message != "user not found" || messages[0].ResponseModel != "" {
+		t.Errorf("unexpected 404:%#v", messages[0])
+	}
+	if messages[1].Code != 409 || messages[1].ResponseModel != "restful.ServiceError" {
+		t.Errorf("unexpected 409:%#v", messages[1])
+	}
+	if messages[2].ResponseModel != "array[swagger.Item]" {
+		t.Errorf("unexpected 422:%#v", messages[2])
+	}
+	if messages[3].ResponseModel != "array[swagger.User]" {
+		t.Errorf("unexpected 423:%#v", messages[3])
+	}
+	if _, ok := decl.Models["restful.ServiceError"]; !ok {
+		t.Error("missing model for ServiceError")
+	}
+	if _, ok := decl.Models["swagger.Item"]; !ok {
+		t.Error("missing model for Item")
+	}
+	for _, each := range []string{"[]swagger.Item", "*[]swagger.User"} {
+		if _, ok := decl.Models[each]; ok {
+			t.Errorf("unexpected model %s", each)
+		}
+	}
+}
+
+func TestOperationAuthorizations(t *testing.T) {
+	check := func(token string) (interface{}, error) { return token, nil }
+	ws := new(restful.WebService)
+	ws.Path("/users")
+	ws.Route(ws.GET("/{id}").To(dummy).Security("apiKey").Scopes("users:read"))
+	ws.Route(ws.DELETE("/{id}").To(dummy))
+	sws := newSwaggerService(Config{
+		WebServices:    []*restful.WebService{ws},
+		Authenticators: []restful.Authenticator{restful.NewAPIKeyAuthenticator("api_key", restful.HEADER_PARAMETER, check)}})
+	decl := sws.composeDeclaration("/users")
+	for _, each := range decl.Apis[0].Operations {
+		if each.HttpMethod == "DELETE" {
+			if each.Authorizations != nil {
+				t.Errorf("unexpected authorizations:%#v", each.Authorizations)
+			}
+			continue
+		}
+		if len(each.Authorizations) != 1 {
+			t.Fatalf("unexpected authorizations:%#v", each.Authorizations)
+		}
+		authorization := each.Authorizations[0]
+		if authorization.LocalOAuth.Scopes[0] != "users:read" || authorization.ApiKey.Keyname != "api_key" || authorization.ApiKey.PassAs != "header" {
+			t.Errorf("unexpected authorization:%#v", authorization)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"reflect"
+	"sort"
 )
 
 type SwaggerService struct {
@@ -83,6 +84,14 @@ func (sws SwaggerService) getListing(req *restful.Request, resp *restful.Respons
 			listing.Apis = append(listing.Apis, ref)
 		}
 	}
+	for _, each := range sws.config.Authenticators {
+		if authorization, ok := asAuthorization(each); ok {
+			if listing.Authorizations == nil {
+				listing.Authorizations = map[string]ApiKey{}
+			}
+			listing.Authorizations[each.SchemeName()] = authorization
+		}
+	}
 	resp.WriteAsJson(listing)
 }
 
@@ -119,7 +128,8 @@ func (sws SwaggerService) composeDeclaration(rootPath string) ApiDeclaration {
 						Nickname: route.Operation}
 
 					operation.Consumes = route.Consumes
-					operation.Produces = route.Produces
+					operation.Produces = producesOf(route)
+					operation.Authorizations = sws.config.operationAuthorizations(route)
 
 					// share root params if any
 					for _, swparam := range rootParams {
@@ -130,6 +140,7 @@ func (sws SwaggerService) composeDeclaration(rootPath string) ApiDeclaration {
 						operation.Parameters = append(operation.Parameters, asSwaggerParameter(param.Data()))
 					}
 					sws.addModelsFromRouteTo(&operation, route, &decl)
+					sws.addResponseMessagesTo(&operation, route, &decl)
 					api.Operations = append(api.Operations, operation)
 				}
 				decl.Apis = append(decl.Apis, api)
@@ -149,6 +160,29 @@ func (sws SwaggerService) addModelsFromRouteTo(operation *Operation, route restf
 	}
 }
 
+// addResponseMessagesTo adds a ResponseMessage for each ResponseError of the Route (ordered by code) and creates the Swagger model of each sample.
+func (sws SwaggerService) addResponseMessagesTo(operation *Operation, route restful.Route, decl *ApiDeclaration) {
+	for _, code := range sortedResponseCodes(route) {
+		each := route.ResponseErrors[code]
+		message := ResponseMessage{Code: code, Message: each.Message}
+		if each.Model != nil {
+			st := reflect.TypeOf(each.Model)
+			if st.Kind() == reflect.Ptr && (st.Elem().Kind() == reflect.Slice || st.Elem().Kind() == reflect.Array) {
+				st = st.Elem()
+			}
+			if st.Kind() == reflect.Slice || st.Kind() == reflect.Array {
+				// the model is that of the element type
+				st = st.Elem()
+				message.ResponseModel = "array[" + st.String() + "]"
+			} else {
+				message.ResponseModel = st.String()
+			}
+			sws.addModelTo(st, decl)
+		}
+		operation.ResponseMessages = append(operation.ResponseMessages, message)
+	}
+}
+
 // addModelFromSample creates and adds (or overwrites) a Model from a sample resource
 func (sws SwaggerService) addModelFromSampleTo(operation *Operation, isResponse bool, sample interface{}, decl *ApiDeclaration) {
 	st := reflect.TypeOf(sample)
@@ -264,6 +298,16 @@ func composeRootPath(req *restful.Request) string {
 	return path + "/" + g
 }
 
+// sortedResponseCodes returns the codes of the ResponseErrors of the Route in increasing order.
+func sortedResponseCodes(route restful.Route) []int {
+	codes := []int{}
+	for code := range route.ResponseErrors {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	return codes
+}
+
 func asFormat(name string) string {
 	return "" // TODO
 }
@@ -278,6 +322,8 @@ func asParamType(kind int) string {
 		return "body"
 	case kind == restful.HEADER_PARAMETER:
 		return "header"
+	case kind == restful.FORM_PARAMETER:
+		return "form"
 	}
 	return ""
 }
@@ -0,0 +1,181 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Timeout sets the maximum duration of calling the filters and the function of each Route of the WebService.
+// A Route can override it using RouteBuilder.Timeout. A negative timeout means none, not even that of the Container.
+func (w *WebService) Timeout(timeout time.Duration) *WebService {
+	w.timeout = timeout
+	return w
+}
+
+// Timeout sets the maximum duration of calling the filters and the function of each Route of all WebServices.
+// A WebService or Route can override it. If a Route function does not complete in time then its Request context
+// is cancelled and, if nothing has been written yet, a ServiceError is written using the ServiceErrorHandler.
+// Writes done by the function after the timeout are discarded ; Shutdown still waits for the function to complete.
+func (c *Container) Timeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// TimeoutStatus changes the status of the ServiceError written when a Route times out.
+// Valid values are http.StatusServiceUnavailable (503, the default) and http.StatusGatewayTimeout (504).
+func (c *Container) TimeoutStatus(status int) {
+	c.timeoutStatus = status
+}
+
+// timeoutOf returns the timeout of the Route, WebService or Container, whichever is set first.
+// Returns zero if a negative timeout (no timeout) is set.
+func (c *Container) timeoutOf(webService *WebService, route *Route) time.Duration {
+	for _, each := range []time.Duration{route.Timeout, webService.timeout} {
+		if each < 0 {
+			return 0
+		}
+		if each > 0 {
+			return each
+		}
+	}
+	return c.timeout
+}
+
+// dispatchWithTimeout calls the filters and function of the Route in a separate goroutine and waits for it
+// to complete or to time out. A panic in that goroutine is propagated to the caller.
+// Writes are suppressed before the context of the Request is cancelled such that a function that stops
+// because of the cancellation cannot race with the timeout response.
+// It returns true if it did not wait for the goroutine to complete ; then the goroutine keeps the request body
+// (and its multipart files) and the lifecycle of the dispatch until it completes, such that Shutdown waits for it.
+func (c *Container) dispatchWithTimeout(timeout time.Duration, webService *WebService, route *Route, httpWriter http.ResponseWriter, httpRequest *http.Request) bool {
+	ctx, cancel := context.WithCancel(httpRequest.Context())
+	defer cancel()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	writer := &timeoutWriter{ResponseWriter: httpWriter, header: httpWriter.Header().Clone()}
+	wrappedRequest, wrappedResponse := route.wrapRequestResponse(writer, httpRequest.WithContext(ctx))
+
+	done, finished := make(chan struct{}), make(chan struct{})
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer close(finished)
+		defer func() {
+			if reason := recover(); reason != nil {
+				panicked <- reason
+			}
+		}()
+		c.dispatchToRoute(webService, route, wrappedRequest, wrappedResponse)
+		close(done)
+	}()
+	select {
+	case <-done:
+		writer.flushHeader()
+		return false
+	case reason := <-panicked:
+		panic(reason)
+	case <-ctx.Done():
+		// the client went away ; nothing can be written
+		writer.timeOut()
+	case <-timer.C:
+		nothingWritten := writer.timeOut()
+		cancel()
+		if nothingWritten {
+			serviceError := NewError(c.timeoutStatus, strconv.Itoa(c.timeoutStatus)+": "+http.StatusText(c.timeoutStatus))
+			errorRequest, errorResponse := newBasicRequestResponse(httpWriter, httpRequest)
+			errorRequest.accessors, errorResponse.accessors = c.entityAccessors, c.entityAccessors
+			c.serviceErrorHandleFunc(serviceError, errorRequest, errorResponse)
+		}
+	}
+	go func() {
+		<-finished
+		c.releaseDispatch(httpRequest)
+	}()
+	return true
+}
+
+// timeoutWriter is a http.ResponseWriter that discards all writes after a timeout.
+// Headers are kept separately (starting with a copy) until the status is written such that the timeout response is not affected by them.
+type timeoutWriter struct {
+	http.ResponseWriter
+	protection  sync.Mutex
+	header      http.Header
+	wroteHeader bool
+	timedOut    bool
+}
+
+// Header is part of http.ResponseWriter
+func (t *timeoutWriter) Header() http.Header {
+	return t.header
+}
+
+// WriteHeader is part of http.ResponseWriter ; it is discarded after a timeout.
+func (t *timeoutWriter) WriteHeader(status int) {
+	t.protection.Lock()
+	defer t.protection.Unlock()
+	if t.timedOut || t.wroteHeader {
+		return
+	}
+	t.writeHeader(status)
+}
+
+// Write is part of http.ResponseWriter ; it returns http.ErrHandlerTimeout after a timeout.
+func (t *timeoutWriter) Write(data []byte) (int, error) {
+	t.protection.Lock()
+	defer t.protection.Unlock()
+	if t.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !t.wroteHeader {
+		t.writeHeader(http.StatusOK)
+	}
+	return t.ResponseWriter.Write(data)
+}
+
+// Flush is part of http.Flusher ; it writes the status (if needed) and flushes unless the request has timed out.
+func (t *timeoutWriter) Flush() {
+	t.protection.Lock()
+	defer t.protection.Unlock()
+	if t.timedOut {
+		return
+	}
+	if !t.wroteHeader {
+		t.writeHeader(http.StatusOK)
+	}
+	if flusher, ok := t.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// writeHeader copies the headers and writes the status ; callers must hold the lock.
+func (t *timeoutWriter) writeHeader(status int) {
+	for key, values := range t.header {
+		t.ResponseWriter.Header()[key] = values
+	}
+	t.wroteHeader = true
+	t.ResponseWriter.WriteHeader(status)
+}
+
+// flushHeader copies the headers if the function completed without writing anything.
+func (t *timeoutWriter) flushHeader() {
+	t.protection.Lock()
+	defer t.protection.Unlock()
+	if !t.wroteHeader {
+		for key, values := range t.header {
+			t.ResponseWriter.Header()[key] = values
+		}
+	}
+}
+
+// timeOut discards all further writes and returns whether nothing has been written yet.
+func (t *timeoutWriter) timeOut() bool {
+	t.protection.Lock()
+	defer t.protection.Unlock()
+	t.timedOut = true
+	return !t.wroteHeader
+}
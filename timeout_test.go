@@ -0,0 +1,161 @@
+package restful
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTimeoutContainer() (*Container, chan error) {
+	container := NewContainer()
+	container.Timeout(time.Hour)
+	lateWrite := make(chan error, 1)
+	ws := new(WebService).Path("/work").Timeout(time.Minute)
+	ws.Route(ws.GET("/slow").Timeout(10 * time.Millisecond).To(func(req *Request, resp *Response) {
+		<-req.Context().Done()
+		resp.AddHeader("X-Late", "true")
+		_, err := resp.Write([]byte("late"))
+		lateWrite <- err
+	}))
+	ws.Route(ws.GET("/fast").To(func(req *Request, resp *Response) {
+		resp.AddHeader("X-Fast", "true")
+		resp.WriteHeader(http.StatusAccepted)
+		resp.Write([]byte("fast"))
+	}))
+	ws.Route(ws.GET("/empty").To(func(req *Request, resp *Response) {
+		resp.AddHeader("X-Empty", "true")
+	}))
+	ws.Route(ws.GET("/panic").To(func(req *Request, resp *Response) {
+		panic("boom")
+	}))
+	container.Add(ws)
+	return container, lateWrite
+}
+
+// go test -v -test.run TestTimeout_Expired ...restful
+func TestTimeout_Expired(t *testing.T) {
+	container, lateWrite := newTimeoutContainer()
+	container.TimeoutStatus(http.StatusGatewayTimeout)
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/work/slow", nil)
+	httpRequest.Header.Set("Accept", MIME_JSON)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != http.StatusGatewayTimeout {
+		t.Fatalf("unexpected status:%d", httpWriter.Code)
+	}
+	if err := <-lateWrite; err != http.ErrHandlerTimeout {
+		t.Errorf("expected late write to fail, got:%v", err)
+	}
+	if httpWriter.Header().Get("X-Late") != "" || httpWriter.Header().Get(HEADER_ContentType) != MIME_JSON {
+		t.Errorf("unexpected headers:%v", httpWriter.Header())
+	}
+}
+
+// go test -v -test.run TestTimeout_InTime ...restful
+func TestTimeout_InTime(t *testing.T) {
+	container, _ := newTimeoutContainer()
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/work/fast", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != http.StatusAccepted || httpWriter.Body.String() != "fast" || httpWriter.Header().Get("X-Fast") != "true" {
+		t.Errorf("unexpected response:%d %s %v", httpWriter.Code, httpWriter.Body.String(), httpWriter.Header())
+	}
+
+	httpRequest, _ = http.NewRequest("GET", "http://here.com/work/empty", nil)
+	httpWriter = httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Header().Get("X-Empty") != "true" {
+		t.Errorf("headers of an empty response should be kept:%v", httpWriter.Header())
+	}
+}
+
+// go test -v -test.run TestTimeout_Panic ...restful
+func TestTimeout_Panic(t *testing.T) {
+	container, _ := newTimeoutContainer()
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/work/panic", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != http.StatusInternalServerError {
+		t.Errorf("unexpected status:%d", httpWriter.Code)
+	}
+}
+
+// closeRecorder is a request body that records whether it is closed.
+type closeRecorder struct {
+	io.Reader
+	closed chan bool
+}
+
+func (c closeRecorder) Close() error {
+	c.closed <- true
+	return nil
+}
+
+// go test -v -test.run TestTimeout_ShutdownWaits ...restful
+func TestTimeout_ShutdownWaits(t *testing.T) {
+	container := NewContainer()
+	release := make(chan bool)
+	ws := new(WebService).Path("/stubborn")
+	ws.Route(ws.POST("").Timeout(10 * time.Millisecond).To(func(req *Request, resp *Response) {
+		<-release // ignores the cancellation
+	}))
+	container.Add(ws)
+
+	body := closeRecorder{Reader: strings.NewReader("{}"), closed: make(chan bool, 1)}
+	httpRequest, _ := http.NewRequest("POST", "http://here.com/stubborn", body)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected status:%d", httpWriter.Code)
+	}
+	if container.ActiveDispatches() != 1 {
+		t.Errorf("the running function should be an active dispatch, got:%d", container.ActiveDispatches())
+	}
+	select {
+	case <-body.closed:
+		t.Error("the body should not be closed while the function is running")
+	default:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := container.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got:%v", err)
+	}
+
+	close(release)
+	if err := container.Shutdown(context.Background()); err != nil {
+		t.Errorf("unexpected error:%v", err)
+	}
+	<-body.closed
+}
+
+func TestTimeoutOf(t *testing.T) {
+	container := NewContainer()
+	container.Timeout(3 * time.Second)
+	ws := new(WebService)
+	route := &Route{}
+	if container.timeoutOf(ws, route) != 3*time.Second {
+		t.Error("expected container timeout")
+	}
+	ws.Timeout(2 * time.Second)
+	if container.timeoutOf(ws, route) != 2*time.Second {
+		t.Error("expected webservice timeout")
+	}
+	route.Timeout = time.Second
+	if container.timeoutOf(ws, route) != time.Second {
+		t.Error("expected route timeout")
+	}
+	route.Timeout = -1
+	if container.timeoutOf(ws, route) != 0 {
+		t.Error("expected no timeout")
+	}
+	route.Timeout = 0
+	ws.Timeout(-1)
+	if container.timeoutOf(ws, route) != 0 {
+		t.Error("expected no webservice timeout")
+	}
+}
@@ -0,0 +1,205 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// TrieRouter expects Routes with paths that contain zero or more parameters in curly brackets.
+// Parameters can be constrained by a regular expression (e.g. {id:[0-9]+}) or match the remainder of the path (e.g. {path:*}).
+// It compiles the paths of all Routes into a trie of path segments such that finding the candidate Routes
+// for a request is proportional to the length of its path rather than to the number of Routes.
+// Method, Consumes and Produces are resolved as for the RouterJSR311, for the most specific path first ;
+// e.g. /users/me is used before /users/{id} unless it has no Route for the method or the Accept header.
+// A TrieRouter must be used as a pointer: container.Router(new(restful.TrieRouter))
+type TrieRouter struct {
+	protection sync.RWMutex
+	root       *trieNode     // nil if not compiled yet or invalidated
+	services   []*WebService // the root is compiled for
+	generation int           // incremented by invalidateRoutes
+}
+
+// trieNode is a path segment in the trie.
+type trieNode struct {
+	literals map[string]*trieNode // static path segments
+	params   []*trieParam         // {param} path segments ; constrained ones first
+	catchAll *trieNode            // a {param:*} path segment
+	routes   []Route              // Routes whose path ends at this node
+	services []*WebService        // the WebService of each of the routes
+	shared   bool                 // whether all routes belong to the same WebService
+}
+
+// trieParam is a parameter path segment with an optional constraint.
+type trieParam struct {
+	constraint string
+	matcher    *regexp.Regexp // nil if unconstrained
+	node       *trieNode
+}
+
+// SelectRoute is part of the Router interface and returns the best match
+// for the WebService and its Route for the given Request.
+func (t *TrieRouter) SelectRoute(
+	webServices []*WebService,
+	httpRequest *http.Request) (selectedService *WebService, selected *Route, err error) {
+
+	matches := t.compiledFor(webServices).collect(tokenizePath(httpRequest.URL.Path), nil)
+	if len(matches) == 0 {
+		return nil, nil, NewError(http.StatusNotFound, "404: Page Not Found")
+	}
+	// try the most specific match first ; a less specific one is only used if it has no Route for the request
+	for _, each := range matches {
+		service, route, detectErr := each.detectRoute(httpRequest)
+		if detectErr == nil {
+			return service, route, nil
+		}
+		if err == nil {
+			// the error of the most specific match is returned if none has a Route
+			selectedService, err = service, detectErr
+		}
+	}
+	return selectedService, nil, err
+}
+
+// detectRoute selects the Route of the node for the request by Method, Consumes and Produces.
+// If the routes belong to different WebServices then only those of the first WebService are considered.
+func (n *trieNode) detectRoute(httpRequest *http.Request) (*WebService, *Route, error) {
+	service := n.services[0]
+	routes := n.routes
+	if !n.shared {
+		routes = []Route{}
+		for i, each := range n.routes {
+			if n.services[i] == service {
+				routes = append(routes, each)
+			}
+		}
+	}
+	route, err := RouterJSR311{}.detectRoute(routes, httpRequest)
+	return service, route, err
+}
+
+// invalidateRoutes is part of the routeCompiler interface ; the trie is rebuilt for the next request.
+func (t *TrieRouter) invalidateRoutes() {
+	t.protection.Lock()
+	t.root = nil
+	t.generation++
+	t.protection.Unlock()
+}
+
+// compiledFor returns the trie for the webServices, compiling it if there is none, it was invalidated
+// or it was compiled for another slice of WebServices. The Container invalidates it whenever a WebService or Route is added.
+func (t *TrieRouter) compiledFor(webServices []*WebService) *trieNode {
+	t.protection.RLock()
+	root, generation := t.root, t.generation
+	if root != nil && !sameWebServices(t.services, webServices) {
+		root = nil
+	}
+	t.protection.RUnlock()
+	if root != nil {
+		return root
+	}
+	root = compileTrie(webServices)
+	t.protection.Lock()
+	if t.generation == generation {
+		// not invalidated while compiling
+		t.root, t.services = root, webServices
+	}
+	t.protection.Unlock()
+	return root
+}
+
+// sameWebServices returns whether both slices are the same, without comparing their elements.
+func sameWebServices(compiled, webServices []*WebService) bool {
+	return len(compiled) == len(webServices) && (len(webServices) == 0 || &compiled[0] == &webServices[0])
+}
+
+// compileTrie returns a trie with all Routes of the webServices.
+func compileTrie(webServices []*WebService) *trieNode {
+	root := new(trieNode)
+	for _, ws := range webServices {
+		for _, each := range ws.routes {
+			root.add(each.pathParts, each.pathMatchers, ws, each)
+		}
+	}
+	return root
+}
+
+// add registers the Route at the node for the path tokens, creating nodes as needed.
+func (n *trieNode) add(tokens []string, matchers []*regexp.Regexp, service *WebService, route Route) {
+	if len(tokens) == 0 {
+		n.shared = len(n.services) == 0 || (n.shared && n.services[0] == service)
+		n.routes = append(n.routes, route)
+		n.services = append(n.services, service)
+		return
+	}
+	var child *trieNode
+	if strings.HasPrefix(tokens[0], "{") {
+		_, constraint := parsePathParameter(tokens[0])
+		if constraint == catchAllConstraint {
+			if n.catchAll == nil {
+				n.catchAll = new(trieNode)
+			}
+			child = n.catchAll
+		} else {
+			child = n.paramNode(constraint, matchers[0])
+		}
+	} else {
+		if n.literals == nil {
+			n.literals = map[string]*trieNode{}
+		}
+		child = n.literals[tokens[0]]
+		if child == nil {
+			child = new(trieNode)
+			n.literals[tokens[0]] = child
+		}
+	}
+	child.add(tokens[1:], matchers[1:], service, route)
+}
+
+// paramNode returns the child node for a parameter with the constraint, creating it if needed.
+// Constrained parameters are kept before the unconstrained one such that they are matched first.
+func (n *trieNode) paramNode(constraint string, matcher *regexp.Regexp) *trieNode {
+	for _, each := range n.params {
+		if each.constraint == constraint {
+			return each.node
+		}
+	}
+	param := &trieParam{constraint, matcher, new(trieNode)}
+	if constraint == "" || len(n.params) == 0 || n.params[len(n.params)-1].constraint != "" {
+		n.params = append(n.params, param)
+	} else {
+		last := n.params[len(n.params)-1]
+		n.params = append(n.params[:len(n.params)-1], param, last)
+	}
+	return param.node
+}
+
+// collect appends all nodes with Routes whose path matches the request tokens.
+// Nodes are ordered by specificity: at each segment, a literal match precedes a constrained parameter match,
+// which precedes an unconstrained parameter match, which precedes a catch-all match.
+func (n *trieNode) collect(tokens []string, collected []*trieNode) []*trieNode {
+	if len(tokens) == 0 {
+		if len(n.routes) > 0 {
+			collected = append(collected, n)
+		}
+	} else {
+		if child, ok := n.literals[tokens[0]]; ok {
+			collected = child.collect(tokens[1:], collected)
+		}
+		for _, each := range n.params {
+			if each.matcher == nil || each.matcher.MatchString(tokens[0]) {
+				collected = each.node.collect(tokens[1:], collected)
+			}
+		}
+	}
+	// a catch-all matches any remainder, including an empty one
+	if n.catchAll != nil && len(n.catchAll.routes) > 0 {
+		collected = append(collected, n.catchAll)
+	}
+	return collected
+}
@@ -0,0 +1,165 @@
+package restful
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var trieSelects = []struct {
+	method, path string
+	found        bool
+	route        string
+}{
+	{"GET", "/", true, "/"},
+	{"GET", "/users", true, "/users/"},
+	{"GET", "/users/", true, "/users/"},
+	{"GET", "/users/1", true, "/users/{id}"},
+	{"POST", "/users/login", true, "/users/login"},
+	{"GET", "/users/login", true, "/users/{id}"},
+	{"GET", "/users/1/orders/2", true, "/users/{id}/orders/{order}"},
+	{"GET", "/network/12", true, "/network/{id}"},
+	{"GET", "/machine/12", true, "/{type}/{id}"},
+	{"GET", "/users/1/missing", false, ""},
+}
+
+func newTrieServices() []*WebService {
+	ws1 := new(WebService).Path("/")
+	ws1.Route(ws1.GET("/").To(trieDummy))
+	ws1.Route(ws1.GET("/{type}/{id}").To(trieDummy))
+	ws1.Route(ws1.GET("/network/{id}").To(trieDummy))
+	ws2 := new(WebService).Path("/users")
+	ws2.Route(ws2.GET("").To(trieDummy))
+	ws2.Route(ws2.GET("/{id}").To(trieDummy))
+	ws2.Route(ws2.POST("/login").To(trieDummy))
+	ws2.Route(ws2.GET("/{id}/orders/{order}").To(trieDummy))
+	return []*WebService{ws1, ws2}
+}
+
+// go test -v -test.run TestTrieRouter_SelectRoute ...restful
+func TestTrieRouter_SelectRoute(t *testing.T) {
+	router := new(TrieRouter)
+	wss := newTrieServices()
+	for _, each := range trieSelects {
+		req, _ := http.NewRequest(each.method, "http://here.com"+each.path, nil)
+		_, route, err := router.SelectRoute(wss, req)
+		if !each.found {
+			if err == nil {
+				t.Errorf("%s %s: error expected, got route:%v", each.method, each.path, route)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %s: unexpected error:%v", each.method, each.path, err)
+			continue
+		}
+		if route.Path != each.route {
+			t.Errorf("%s %s: expected route:%s, actual:%s", each.method, each.path, each.route, route.Path)
+		}
+	}
+}
+
+// go test -v -test.run TestTrieRouter_Constraints ...restful
+func TestTrieRouter_Constraints(t *testing.T) {
+	ws1 := new(WebService).Path("/items")
+	ws1.Route(ws1.GET("/{name}").To(trieDummy))
+	ws1.Route(ws1.GET("/{id:[0-9]+}").To(trieDummy))
+	ws1.Route(ws1.GET("/{rest:*}").To(trieDummy))
+	fixtures := map[string]string{
+		"/items/12":    "/items/{id:[0-9]+}",
+		"/items/ab":    "/items/{name}",
+		"/items/ab/cd": "/items/{rest:*}",
+		"/items":       "/items/{rest:*}",
+	}
+	router := new(TrieRouter)
+	for path, expected := range fixtures {
+		req, _ := http.NewRequest("GET", "http://here.com"+path, nil)
+		_, route, err := router.SelectRoute([]*WebService{ws1}, req)
+		if err != nil {
+			t.Errorf("%s: unexpected error:%v", path, err)
+			continue
+		}
+		if route.Path != expected {
+			t.Errorf("%s: expected route:%s, actual:%s", path, expected, route.Path)
+		}
+	}
+}
+
+// go test -v -test.run TestTrieRouter_LiteralBeforeParameter ...restful
+func TestTrieRouter_LiteralBeforeParameter(t *testing.T) {
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{id}").Produces(MIME_JSON).To(trieDummy))
+	ws.Route(ws.GET("/me").Produces(MIME_XML).To(trieDummy))
+	router := new(TrieRouter)
+	for _, each := range []struct {
+		path, accept, route string
+		status              int
+	}{
+		{"/users/me", "application/json, application/xml;q=0.5", "/users/me", 0},
+		{"/users/me", MIME_XML, "/users/me", 0},
+		{"/users/me", MIME_JSON, "/users/{id}", 0},
+		{"/users/42", MIME_JSON, "/users/{id}", 0},
+		{"/users/me", "text/plain", "", http.StatusNotAcceptable},
+	} {
+		req, _ := http.NewRequest("GET", "http://here.com"+each.path, nil)
+		req.Header.Set(HEADER_Accept, each.accept)
+		_, route, err := router.SelectRoute([]*WebService{ws}, req)
+		if each.status != 0 {
+			if serviceError, ok := err.(ServiceError); !ok || serviceError.Code != each.status {
+				t.Errorf("%s %s: expected %d, got:%v", each.path, each.accept, each.status, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %s: unexpected error:%v", each.path, each.accept, err)
+			continue
+		}
+		if route.Path != each.route {
+			t.Errorf("%s %s: expected route:%s, actual:%s", each.path, each.accept, each.route, route.Path)
+		}
+	}
+}
+
+// go test -v -test.run TestTrieRouter_MethodNotAllowed ...restful
+func TestTrieRouter_MethodNotAllowed(t *testing.T) {
+	req, _ := http.NewRequest("DELETE", "http://here.com/users/login", nil)
+	_, _, err := new(TrieRouter).SelectRoute(newTrieServices(), req)
+	if err == nil {
+		t.Fatal("error expected")
+	}
+	if err.(ServiceError).Code != http.StatusMethodNotAllowed {
+		t.Errorf("405 expected, got:%v", err)
+	}
+}
+
+// go test -v -test.run TestTrieRouter_RoutesAddedLater ...restful
+func TestTrieRouter_RoutesAddedLater(t *testing.T) {
+	container := NewContainer()
+	container.Router(new(TrieRouter))
+	ws := new(WebService).Path("/later")
+	container.Add(ws)
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		io.WriteString(resp, req.PathParameter("id"))
+	}))
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/later/42", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if "42" != httpWriter.Body.String() {
+		t.Fatalf("expected: 42 but got:%s (%d)", httpWriter.Body.String(), httpWriter.Code)
+	}
+	// a second WebService without Routes and a Route added later leave the Route count of the first unchanged
+	other := new(WebService).Path("/other")
+	container.Add(other)
+	other.Route(other.GET("/{name}").To(func(req *Request, resp *Response) {
+		io.WriteString(resp, "other "+req.PathParameter("name"))
+	}))
+	httpRequest, _ = http.NewRequest("GET", "http://here.com/other/x", nil)
+	httpWriter = httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if "other x" != httpWriter.Body.String() {
+		t.Fatalf("expected: other x but got:%s (%d)", httpWriter.Body.String(), httpWriter.Code)
+	}
+}
+
+func trieDummy(req *Request, resp *Response) { io.WriteString(resp.ResponseWriter, "trieDummy") }
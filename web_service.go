@@ -6,6 +6,7 @@ package restful
 
 import (
 	"log"
+	"time"
 )
 
 // WebService holds a collection of Route values that bind a Http Method + URL Path to a function.
@@ -18,6 +19,12 @@ type WebService struct {
 	pathParameters []*Parameter
 	filters        []FilterFunction
 	documentation  string
+	accessors      *entityAccessRegistry // of the Container this WebService is added to
+	timeout        time.Duration         // zero means the timeout of the Container applies
+	security       []string              // names of the authentication schemes for Routes that declare none
+	roles, scopes  []string              // authorization requirements for Routes that declare none
+	maxBodySize    int64                 // zero means the maximum of the Container applies
+	routesChanged  func()                // set by the Container this WebService is added to
 }
 
 // Path specifies the root URL template path of the WebService.
@@ -73,13 +80,44 @@ func (w *WebService) HeaderParameter(name, description string) *Parameter {
 	return p
 }
 
+// FormParameter creates a new Parameter of kind Form for documentation purposes.
+// It is initialized as not required with string as its DataType ; use DataType("file") for a file upload.
+// The Route should consume MIME_MultipartForm or MIME_URLEncodedForm.
+func (w *WebService) FormParameter(name, description string) *Parameter {
+	p := &Parameter{&ParameterData{Name: name, Description: description, Required: false, DataType: "string"}}
+	p.beForm()
+	return p
+}
+
 // Route creates a new Route using the RouteBuilder and add to the ordered list of Routes.
 func (w *WebService) Route(builder *RouteBuilder) *WebService {
 	builder.copyDefaults(w.produces, w.consumes)
-	w.routes = append(w.routes, builder.Build())
+	if len(builder.security) == 0 {
+		builder.security = w.security
+	}
+	if len(builder.roles) == 0 {
+		builder.roles = w.roles
+	}
+	if len(builder.scopes) == 0 {
+		builder.scopes = w.scopes
+	}
+	route := builder.Build()
+	route.accessors = w.accessors
+	w.routes = append(w.routes, route)
+	if w.routesChanged != nil {
+		w.routesChanged()
+	}
 	return w
 }
 
+// useEntityAccessors makes this WebService and all its Routes use the registry for reading and writing entities.
+func (w *WebService) useEntityAccessors(registry *entityAccessRegistry) {
+	w.accessors = registry
+	for i := range w.routes {
+		w.routes[i].accessors = registry
+	}
+}
+
 // Method creates a new RouteBuilder and initialize its http method
 func (w *WebService) Method(httpMethod string) *RouteBuilder {
 	return new(RouteBuilder).servicePath(w.rootPath).Method(httpMethod)
@@ -99,6 +137,27 @@ func (w *WebService) Consumes(accepts ...string) *WebService {
 	return w
 }
 
+// Security declares the names of the authentication schemes (see Authenticator) that apply to Routes
+// added after this call that do not declare their own.
+func (w *WebService) Security(schemeNames ...string) *WebService {
+	w.security = schemeNames
+	return w
+}
+
+// Roles declares that the principal of a request must have one of the roles (see RoleHolder)
+// for Routes added after this call that do not declare their own.
+func (w *WebService) Roles(roles ...string) *WebService {
+	w.roles = roles
+	return w
+}
+
+// Scopes declares that the principal of a request must have all of the (OAuth) scopes (see ScopeHolder)
+// for Routes added after this call that do not declare their own.
+func (w *WebService) Scopes(scopes ...string) *WebService {
+	w.scopes = scopes
+	return w
+}
+
 // Routes returns the Routes associated with this WebService
 func (w WebService) Routes() []Route {
 	return w.routes
@@ -1,6 +1,8 @@
 package restful
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -69,6 +71,76 @@ func TestMethodNotAllowed(t *testing.T) {
 	}
 }
 
+func TestMethodNotAllowed_AllowHeader(t *testing.T) {
+	tearDown()
+	ws := new(WebService).Path("")
+	ws.Route(ws.GET("/get").To(dummy))
+	ws.Route(ws.PUT("/get").To(dummy))
+	ws.Route(ws.GET("/get").Produces(MIME_XML).To(dummy))
+	Add(ws)
+	httpRequest, _ := http.NewRequest("POST", "http://here.com/get", nil)
+	httpWriter := httptest.NewRecorder()
+	DefaultContainer.dispatch(httpWriter, httpRequest)
+	if 405 != httpWriter.Code {
+		t.Fatal("405 expected method not allowed")
+	}
+	if allow := httpWriter.Header().Get(HEADER_Allow); allow != "GET,PUT,HEAD" {
+		t.Errorf("unexpected Allow header:%s", allow)
+	}
+}
+
+func TestNotFound_ServiceErrorEntity(t *testing.T) {
+	tearDown()
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/missing", nil)
+	httpRequest.Header.Set("Accept", "application/xml")
+	httpWriter := httptest.NewRecorder()
+	DefaultContainer.dispatch(httpWriter, httpRequest)
+	if 404 != httpWriter.Code {
+		t.Fatal("404 expected on missing")
+	}
+	if ct := httpWriter.Header().Get(HEADER_ContentType); ct != MIME_XML {
+		t.Errorf("unexpected content-type:%s", ct)
+	}
+	serviceError := ServiceError{}
+	if err := xml.Unmarshal(httpWriter.Body.Bytes(), &serviceError); err != nil {
+		t.Fatal(err)
+	}
+	if serviceError.Code != 404 {
+		t.Errorf("unexpected entity:%v", serviceError)
+	}
+}
+
+func TestServiceErrorHandler(t *testing.T) {
+	container := NewContainer()
+	container.ServiceErrorHandler(func(serviceError ServiceError, req *Request, resp *Response) {
+		resp.WriteErrorString(serviceError.Code, "custom")
+	})
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/missing", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if 404 != httpWriter.Code || "custom" != httpWriter.Body.String() {
+		t.Errorf("unexpected response:%d %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+func TestWriteProblemDetails(t *testing.T) {
+	container := NewContainer()
+	container.ServiceErrorHandler(WriteProblemDetails)
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/missing?q=1", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if ct := httpWriter.Header().Get(HEADER_ContentType); ct != MIME_ProblemJSON {
+		t.Fatalf("unexpected content-type:%s", ct)
+	}
+	problem := ProblemDetails{}
+	if err := json.Unmarshal(httpWriter.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Status != 404 || problem.Title != "Not Found" || problem.Instance != "/missing?q=1" {
+		t.Errorf("unexpected problem:%#v", problem)
+	}
+}
+
 func newPanicingService() *WebService {
 	ws := new(WebService).Path("")
 	ws.Route(ws.GET("/fire").To(doPanic))
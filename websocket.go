@@ -0,0 +1,464 @@
+package restful
+
+// Copyright 2013 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Message types of a WebSocketConn, see RFC 6455
+const (
+	WebSocketTextMessage   = 1 // UTF-8 encoded text
+	WebSocketBinaryMessage = 2
+)
+
+// Close codes of a WebSocketConn, see RFC 6455 section 7.4.1
+const (
+	WebSocketCloseNormalClosure      = 1000
+	WebSocketCloseGoingAway          = 1001
+	WebSocketCloseProtocolError      = 1002
+	WebSocketCloseUnsupportedData    = 1003
+	WebSocketCloseNoStatusReceived   = 1005 // never sent ; reported if the close frame has no code
+	WebSocketCloseInvalidPayloadData = 1007
+	WebSocketClosePolicyViolation    = 1008
+	WebSocketCloseMessageTooBig      = 1009
+	WebSocketCloseInternalError      = 1011
+)
+
+const (
+	webSocketGUID             = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	webSocketDefaultReadLimit = 32 << 20
+	webSocketMaxControlSize   = 125
+
+	opContinuation = 0
+	opClose        = 8
+	opPing         = 9
+	opPong         = 10
+)
+
+// WebSocketFunction is the function of a WebSocket Route, called with the upgraded connection.
+// The connection is closed (if needed) when the function returns.
+type WebSocketFunction func(*WebSocketConn, *Request)
+
+// WebSocketCloseError is returned by ReadMessage when the peer has closed the connection.
+type WebSocketCloseError struct {
+	Code int
+	Text string
+}
+
+// Error is part of error interface
+func (e *WebSocketCloseError) Error() string {
+	return "[restful] websocket closed: " + strconv.Itoa(e.Code) + " " + e.Text
+}
+
+// WebSocketUpgrader performs the opening handshake of the WebSocket protocol (version 13).
+// Its zero value is usable: no subprotocols, same origin only and messages up to 32MB.
+type WebSocketUpgrader struct {
+	// Subprotocols in order of preference ; the first one also requested by the client is selected.
+	Subprotocols []string
+	// CheckOrigin returns whether the Origin of the request is allowed.
+	// If nil then requests with an Origin header must have the same host as the request.
+	CheckOrigin func(*http.Request) bool
+	// ReadLimit is the maximum size in bytes of a received message ; zero means 32MB.
+	ReadLimit int64
+}
+
+// ToWebSocket binds the function of a WebSocket Route, using a zero WebSocketUpgrader.
+// The Route must use GET. Filters are processed before the upgrade, e.g. to authenticate the request.
+//
+//	ws.Route(ws.GET("/chat").Filter(auth.Filter).ToWebSocket(chat))
+func (b *RouteBuilder) ToWebSocket(function WebSocketFunction) *RouteBuilder {
+	return b.ToWebSocketWith(WebSocketUpgrader{}, function)
+}
+
+// ToWebSocketWith binds the function of a WebSocket Route, using the upgrader to customize the upgrade.
+// The Route is marked as a WebSocket Route such that the request timeout and content encoding do not apply.
+func (b *RouteBuilder) ToWebSocketWith(upgrader WebSocketUpgrader, function WebSocketFunction) *RouteBuilder {
+	b.To(upgrader.RouteFunction(function))
+	b.webSocket = true
+	return b
+}
+
+// RouteFunction returns a RouteFunction that upgrades the connection and calls the function.
+// A Route bound to it using RouteBuilder.To is not marked as a WebSocket Route, so the request timeout
+// still applies ; prefer RouteBuilder.ToWebSocketWith.
+func (u WebSocketUpgrader) RouteFunction(function WebSocketFunction) RouteFunction {
+	return func(req *Request, resp *Response) {
+		conn, err := u.Upgrade(req, resp)
+		if err != nil {
+			return
+		}
+		defer conn.Close(WebSocketCloseNormalClosure, "")
+		function(conn, req)
+	}
+}
+
+// Upgrade validates the handshake request, takes over the connection and writes the 101 response,
+// including the headers set on the Response (e.g. by filters).
+// If the request is not a valid handshake then it writes an error response (400, 403 or 426) and returns an error.
+func (u WebSocketUpgrader) Upgrade(req *Request, resp *Response) (*WebSocketConn, error) {
+	httpRequest := req.Request
+	if httpRequest.Method != "GET" ||
+		!headerContainsToken(httpRequest.Header, "Connection", "upgrade") ||
+		!headerContainsToken(httpRequest.Header, "Upgrade", "websocket") {
+		return nil, u.fail(resp, http.StatusBadRequest, "not a websocket handshake")
+	}
+	if httpRequest.Header.Get("Sec-WebSocket-Version") != "13" {
+		resp.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, u.fail(resp, http.StatusUpgradeRequired, "unsupported websocket version")
+	}
+	key := httpRequest.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, u.fail(resp, http.StatusBadRequest, "invalid Sec-WebSocket-Key")
+	}
+	checkOrigin := u.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = isSameOrigin
+	}
+	if !checkOrigin(httpRequest) {
+		return nil, u.fail(resp, http.StatusForbidden, "origin not allowed")
+	}
+	subprotocol := u.selectSubprotocol(httpRequest)
+
+	netConn, buffered, err := resp.Hijack()
+	if err != nil {
+		return nil, u.fail(resp, http.StatusInternalServerError, err.Error())
+	}
+	resp.statusCode = http.StatusSwitchingProtocols
+	handshake := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + webSocketAccept(key) + "\r\n"
+	if subprotocol != "" {
+		handshake += "Sec-WebSocket-Protocol: " + subprotocol + "\r\n"
+	}
+	buffered.WriteString(handshake)
+	resp.Header().Write(buffered)
+	buffered.WriteString("\r\n")
+	if err := buffered.Flush(); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	readLimit := u.ReadLimit
+	if readLimit <= 0 {
+		readLimit = webSocketDefaultReadLimit
+	}
+	return &WebSocketConn{
+		conn:        netConn,
+		reader:      buffered.Reader,
+		writer:      buffered.Writer,
+		readLimit:   readLimit,
+		subprotocol: subprotocol,
+	}, nil
+}
+
+// fail writes the ServiceError for a rejected handshake and returns the reason as an error.
+func (u WebSocketUpgrader) fail(resp *Response, status int, reason string) error {
+	resp.writeServiceError(NewError(status, strconv.Itoa(status)+": "+http.StatusText(status)))
+	return errors.New("[restful] websocket upgrade failed: " + reason)
+}
+
+// selectSubprotocol returns the first of the Subprotocols that is requested by the client, if any.
+func (u WebSocketUpgrader) selectSubprotocol(httpRequest *http.Request) string {
+	requested := []string{}
+	for _, each := range httpRequest.Header["Sec-Websocket-Protocol"] {
+		for _, protocol := range strings.Split(each, ",") {
+			requested = append(requested, strings.TrimSpace(protocol))
+		}
+	}
+	for _, each := range u.Subprotocols {
+		if containsString(requested, each) {
+			return each
+		}
+	}
+	return ""
+}
+
+// WebSocketConn is an upgraded connection that sends and receives WebSocket messages.
+// Messages must be read by one goroutine at a time ; writes may be done concurrently.
+// Ping frames are answered automatically while reading.
+type WebSocketConn struct {
+	conn        net.Conn
+	reader      *bufio.Reader
+	readLimit   int64
+	subprotocol string
+	pongHandler func(data []byte)
+
+	writeLock sync.Mutex
+	writer    *bufio.Writer
+	closeSent bool
+}
+
+// Subprotocol returns the negotiated subprotocol ; empty if none.
+func (c *WebSocketConn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// RemoteAddr returns the network address of the client.
+func (c *WebSocketConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetReadDeadline sets the deadline for reading messages, e.g. to detect clients that went away.
+func (c *WebSocketConn) SetReadDeadline(deadline time.Time) error {
+	return c.conn.SetReadDeadline(deadline)
+}
+
+// SetWriteDeadline sets the deadline for writing messages.
+func (c *WebSocketConn) SetWriteDeadline(deadline time.Time) error {
+	return c.conn.SetWriteDeadline(deadline)
+}
+
+// SetPongHandler sets the function that is called (while reading) for each received pong.
+func (c *WebSocketConn) SetPongHandler(handler func(data []byte)) {
+	c.pongHandler = handler
+}
+
+// ReadMessage returns the next text or binary message, combining fragmented frames.
+// If the peer closes the connection then a close frame is sent back and the error is a *WebSocketCloseError.
+// If the peer violates the protocol then the connection is closed with the appropriate close code.
+func (c *WebSocketConn) ReadMessage() (messageType int, data []byte, err error) {
+	for {
+		frame, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch frame.opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, frame.payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			if c.pongHandler != nil {
+				c.pongHandler(frame.payload)
+			}
+			continue
+		case opClose:
+			closeErr := parseWebSocketClose(frame.payload)
+			code := closeErr.Code
+			if code == WebSocketCloseNoStatusReceived {
+				code = WebSocketCloseNormalClosure
+			}
+			c.Close(code, "")
+			return 0, nil, closeErr
+		case opContinuation:
+			if messageType == 0 {
+				return 0, nil, c.failProtocol(WebSocketCloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			if messageType != 0 {
+				return 0, nil, c.failProtocol(WebSocketCloseProtocolError, "expected continuation frame")
+			}
+			messageType = frame.opcode
+		}
+		if int64(len(data))+int64(len(frame.payload)) > c.readLimit {
+			return 0, nil, c.failProtocol(WebSocketCloseMessageTooBig, "message too big")
+		}
+		data = append(data, frame.payload...)
+		if frame.final {
+			if messageType == WebSocketTextMessage && !utf8.Valid(data) {
+				return 0, nil, c.failProtocol(WebSocketCloseInvalidPayloadData, "invalid UTF-8 text")
+			}
+			return messageType, data, nil
+		}
+	}
+}
+
+// WriteMessage sends the data as a single text or binary frame.
+func (c *WebSocketConn) WriteMessage(messageType int, data []byte) error {
+	if messageType != WebSocketTextMessage && messageType != WebSocketBinaryMessage {
+		return errors.New("[restful] invalid websocket message type:" + strconv.Itoa(messageType))
+	}
+	return c.writeFrame(messageType, data)
+}
+
+// Ping sends a ping frame ; the pong is received by ReadMessage (see SetPongHandler).
+func (c *WebSocketConn) Ping(data []byte) error {
+	return c.writeFrame(opPing, data)
+}
+
+// Close sends a close frame with the code and reason (unless already sent) and closes the connection.
+func (c *WebSocketConn) Close(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	if len(payload) > webSocketMaxControlSize {
+		payload = payload[:webSocketMaxControlSize]
+	}
+	err := c.writeFrame(opClose, payload)
+	if err == errWebSocketCloseSent {
+		err = nil
+	}
+	c.conn.Close()
+	return err
+}
+
+// errWebSocketCloseSent is returned when writing after the close frame was sent.
+var errWebSocketCloseSent = errors.New("[restful] websocket close frame already sent")
+
+// failProtocol closes the connection with the code and returns the reason as a *WebSocketCloseError.
+func (c *WebSocketConn) failProtocol(code int, reason string) error {
+	c.Close(code, reason)
+	return &WebSocketCloseError{Code: code, Text: reason}
+}
+
+// webSocketFrame is a received frame with unmasked payload.
+type webSocketFrame struct {
+	final   bool
+	opcode  int
+	payload []byte
+}
+
+// readFrame reads and validates the next frame from the client.
+func (c *WebSocketConn) readFrame() (webSocketFrame, error) {
+	var frame webSocketFrame
+	header := make([]byte, 2, 8)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return frame, err
+	}
+	frame.final = header[0]&0x80 != 0
+	frame.opcode = int(header[0] & 0x0f)
+	if header[0]&0x70 != 0 {
+		return frame, c.failProtocol(WebSocketCloseProtocolError, "reserved bits set")
+	}
+	switch frame.opcode {
+	case opContinuation, WebSocketTextMessage, WebSocketBinaryMessage:
+	case opClose, opPing, opPong:
+		if !frame.final || header[1]&0x7f > webSocketMaxControlSize {
+			return frame, c.failProtocol(WebSocketCloseProtocolError, "invalid control frame")
+		}
+	default:
+		return frame, c.failProtocol(WebSocketCloseProtocolError, "unknown opcode "+strconv.Itoa(frame.opcode))
+	}
+	if header[1]&0x80 == 0 {
+		return frame, c.failProtocol(WebSocketCloseProtocolError, "client frames must be masked")
+	}
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return frame, err
+		}
+		length = int64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return frame, err
+		}
+		length = int64(binary.BigEndian.Uint64(extended))
+	}
+	if length < 0 || length > c.readLimit {
+		return frame, c.failProtocol(WebSocketCloseMessageTooBig, "message too big")
+	}
+	mask := make([]byte, 4)
+	if _, err := io.ReadFull(c.reader, mask); err != nil {
+		return frame, err
+	}
+	frame.payload = make([]byte, length)
+	if _, err := io.ReadFull(c.reader, frame.payload); err != nil {
+		return frame, err
+	}
+	for i := range frame.payload {
+		frame.payload[i] ^= mask[i%4]
+	}
+	return frame, nil
+}
+
+// writeFrame sends a single unmasked frame ; nothing can be sent after a close frame.
+func (c *WebSocketConn) writeFrame(opcode int, payload []byte) error {
+	if opcode >= opClose && len(payload) > webSocketMaxControlSize {
+		return errors.New("[restful] websocket control frame too big")
+	}
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	if c.closeSent {
+		return errWebSocketCloseSent
+	}
+	if opcode == opClose {
+		c.closeSent = true
+	}
+	header := []byte{0x80 | byte(opcode), 0}
+	switch length := len(payload); {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xffff:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header[1] = 127
+		header = append(header, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+	c.writer.Write(header)
+	c.writer.Write(payload)
+	return c.writer.Flush()
+}
+
+// parseWebSocketClose returns the code and reason of a close frame payload.
+// An invalid payload results in WebSocketCloseProtocolError.
+func parseWebSocketClose(payload []byte) *WebSocketCloseError {
+	if len(payload) == 0 {
+		return &WebSocketCloseError{Code: WebSocketCloseNoStatusReceived}
+	}
+	if len(payload) == 1 || !utf8.Valid(payload[2:]) {
+		return &WebSocketCloseError{Code: WebSocketCloseProtocolError, Text: "invalid close frame"}
+	}
+	code := int(binary.BigEndian.Uint16(payload))
+	if !isValidWebSocketCloseCode(code) {
+		return &WebSocketCloseError{Code: WebSocketCloseProtocolError, Text: "invalid close code " + strconv.Itoa(code)}
+	}
+	return &WebSocketCloseError{Code: code, Text: string(payload[2:])}
+}
+
+// isValidWebSocketCloseCode returns whether the code may be sent in a close frame.
+func isValidWebSocketCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011, code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+// webSocketAccept returns the value of the Sec-WebSocket-Accept header for the key of the request.
+func webSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// isSameOrigin returns whether the request has no Origin header or one with the host of the request.
+func isSameOrigin(httpRequest *http.Request) bool {
+	origin := httpRequest.Header.Get(HEADER_Origin)
+	if origin == "" {
+		return true
+	}
+	originURL, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(originURL.Host, httpRequest.Host)
+}
+
+// headerContainsToken returns whether any of the comma separated values of the header equals the token (case-insensitive).
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, each := range header[http.CanonicalHeaderKey(name)] {
+		for _, value := range strings.Split(each, ",") {
+			if strings.EqualFold(strings.TrimSpace(value), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
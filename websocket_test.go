@@ -0,0 +1,215 @@
+package restful
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testWebSocketClient is a minimal client that sends masked frames.
+type testWebSocketClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialTestWebSocket(t *testing.T, url string, header string) (*testWebSocketClient, string) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+	io.WriteString(conn, "GET /chat HTTP/1.1\r\nHost: "+strings.TrimPrefix(url, "http://")+"\r\n"+
+		"Connection: keep-alive, Upgrade\r\nUpgrade: websocket\r\nSec-WebSocket-Version: 13\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+header+"\r\n")
+	reader := bufio.NewReader(conn)
+	var response strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line == "\r\n" {
+			break
+		}
+		response.WriteString(line)
+	}
+	return &testWebSocketClient{conn: conn, reader: reader}, response.String()
+}
+
+func (c *testWebSocketClient) send(first byte, payload []byte) {
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{first, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, each := range payload {
+		frame = append(frame, each^mask[i%4])
+	}
+	c.conn.Write(frame)
+}
+
+func (c *testWebSocketClient) receive(t *testing.T) (byte, []byte) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		t.Fatal(err)
+	}
+	payload := make([]byte, header[1]&0x7f)
+	io.ReadFull(c.reader, payload)
+	return header[0], payload
+}
+
+// go test -v -test.run TestWebSocketRoute ...restful
+func TestWebSocketRoute(t *testing.T) {
+	closed := make(chan error, 1)
+	container := NewContainer()
+	container.EnableContentEncoding(true)
+	container.Timeout(time.Millisecond)
+	container.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		if req.Request.Header.Get("X-Token") != "secret" {
+			resp.WriteErrorString(http.StatusUnauthorized, "401: Unauthorized")
+			return
+		}
+		resp.Header().Set("X-Filtered", "true")
+		chain.ProcessFilter(req, resp)
+	})
+	ws := new(WebService)
+	ws.Route(ws.GET("/chat").ToWebSocket(func(conn *WebSocketConn, req *Request) {
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				closed <- err
+				return
+			}
+			conn.WriteMessage(messageType, append([]byte("echo:"), data...))
+		}
+	}))
+	container.Add(ws)
+	server := httptest.NewServer(container)
+	defer server.Close()
+
+	if _, response := dialTestWebSocket(t, server.URL, ""); !strings.HasPrefix(response, "HTTP/1.1 401") {
+		t.Fatalf("expected filter to reject the upgrade, got %s", response)
+	}
+	client, response := dialTestWebSocket(t, server.URL, "X-Token: secret\r\nAccept-Encoding: gzip\r\n")
+	if !strings.HasPrefix(response, "HTTP/1.1 101") ||
+		!strings.Contains(response, "Sec-WebSocket-Accept: s3pPLMBiTxaQ9kYGzzhZRbK+xOo=") ||
+		!strings.Contains(response, "X-Filtered: true") {
+		t.Fatalf("unexpected handshake response:%s", response)
+	}
+	time.Sleep(5 * time.Millisecond) // the connection outlives the timeout
+
+	client.send(0x81, []byte("hello"))
+	if first, payload := client.receive(t); first != 0x81 || string(payload) != "echo:hello" {
+		t.Errorf("unexpected text echo:%x %q", first, payload)
+	}
+	client.send(0x02, []byte{1, 2})
+	client.send(0x89, []byte("ping"))
+	client.send(0x80, []byte{3})
+	if first, payload := client.receive(t); first != 0x8a || string(payload) != "ping" {
+		t.Errorf("expected pong, got %x %q", first, payload)
+	}
+	if first, payload := client.receive(t); first != 0x82 || string(payload) != "echo:\x01\x02\x03" {
+		t.Errorf("unexpected fragmented binary echo:%x %q", first, payload)
+	}
+	closing := make([]byte, 2)
+	binary.BigEndian.PutUint16(closing, WebSocketCloseGoingAway)
+	client.send(0x88, closing)
+	if first, payload := client.receive(t); first != 0x88 || binary.BigEndian.Uint16(payload) != WebSocketCloseGoingAway {
+		t.Errorf("expected close frame echo, got %x %v", first, payload)
+	}
+	if err, ok := (<-closed).(*WebSocketCloseError); !ok || err.Code != WebSocketCloseGoingAway {
+		t.Errorf("expected WebSocketCloseError, got %v", err)
+	}
+}
+
+func TestWebSocketProtocolError(t *testing.T) {
+	container := NewContainer()
+	ws := new(WebService)
+	ws.Route(ws.GET("/chat").ToWebSocket(func(conn *WebSocketConn, req *Request) {
+		conn.ReadMessage()
+	}))
+	container.Add(ws)
+	server := httptest.NewServer(container)
+	defer server.Close()
+
+	client, _ := dialTestWebSocket(t, server.URL, "")
+	client.conn.Write([]byte{0x81, 0x01, 'x'}) // unmasked
+	if first, payload := client.receive(t); first != 0x88 || binary.BigEndian.Uint16(payload) != WebSocketCloseProtocolError {
+		t.Errorf("expected protocol error close, got %x %v", first, payload)
+	}
+}
+
+func TestWebSocketUpgradeRejected(t *testing.T) {
+	for i, each := range []struct {
+		header map[string]string
+		status int
+	}{
+		{map[string]string{}, 400},
+		{map[string]string{"Sec-WebSocket-Version": "8"}, 426},
+		{map[string]string{"Sec-WebSocket-Key": "short"}, 400},
+		{map[string]string{"Origin": "http://evil.com"}, 403},
+		{map[string]string{"Origin": "http://here.com"}, 500}, // the recorder cannot be hijacked
+	} {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com/chat", nil)
+		if len(each.header) > 0 {
+			httpRequest.Header.Set("Connection", "Upgrade")
+			httpRequest.Header.Set("Upgrade", "websocket")
+			httpRequest.Header.Set("Sec-WebSocket-Version", "13")
+			httpRequest.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		}
+		for key, value := range each.header {
+			httpRequest.Header.Set(key, value)
+		}
+		httpWriter := httptest.NewRecorder()
+		if _, err := (WebSocketUpgrader{}).Upgrade(newRequest(httpRequest), newResponse(httpWriter)); err == nil || httpWriter.Code != each.status {
+			t.Errorf("%d: expected %d got %d %v", i, each.status, httpWriter.Code, err)
+		}
+	}
+	// the ServiceErrorHandler of the Container is used, if known
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/chat", nil)
+	resp := newResponse(httptest.NewRecorder())
+	var handled ServiceError
+	resp.serviceErrorHandler = func(serviceError ServiceError) { handled = serviceError }
+	if _, err := (WebSocketUpgrader{}).Upgrade(newRequest(httpRequest), resp); err == nil || handled.Code != 400 {
+		t.Errorf("expected the 400 to be handled, got:%v %v", handled, err)
+	}
+}
+
+func TestSelectSubprotocol(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/chat", nil)
+	httpRequest.Header.Set("Sec-WebSocket-Protocol", "v1.chat, v2.chat")
+	if protocol := (WebSocketUpgrader{Subprotocols: []string{"v2.chat", "v1.chat"}}).selectSubprotocol(httpRequest); protocol != "v2.chat" {
+		t.Errorf("expected v2.chat got %s", protocol)
+	}
+	if protocol := (WebSocketUpgrader{Subprotocols: []string{"v3.chat"}}).selectSubprotocol(httpRequest); protocol != "" {
+		t.Errorf("expected no subprotocol got %s", protocol)
+	}
+}
+
+// go test -v -test.run TestWebSocketHeaderDoesNotSkipTimeout ...restful
+func TestWebSocketHeaderDoesNotSkipTimeout(t *testing.T) {
+	container, lateWrite := newTimeoutContainer()
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/work/slow", nil)
+	httpRequest.Header.Set("Connection", "Upgrade")
+	httpRequest.Header.Set("Upgrade", "websocket")
+	httpRequest.Header.Set(HEADER_Accept, MIME_JSON)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if httpWriter.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected a plain Route to time out, got %d", httpWriter.Code)
+	}
+	if err := <-lateWrite; err != http.ErrHandlerTimeout {
+		t.Errorf("expected late write to fail, got:%v", err)
+	}
+	ws := new(WebService)
+	if route := ws.GET("/chat").ToWebSocket(func(*WebSocketConn, *Request) {}).Build(); !route.WebSocket {
+		t.Error("expected a WebSocket Route")
+	}
+	if route := ws.GET("/chat").ToWebSocket(func(*WebSocketConn, *Request) {}).To(dummy).Build(); route.WebSocket {
+		t.Error("expected To to bind a plain Route")
+	}
+}